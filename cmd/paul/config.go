@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Spazzy757/paul/pkg/config"
+	"github.com/Spazzy757/paul/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultConfigPath = ".github/PAUL.yaml"
+	usage             = `Usage:
+  paul                              start the server
+  paul config validate [PAUL.yaml]  validate a config and print it with defaults applied
+  paul config schema                print the JSON Schema for PAUL.yaml
+`
+)
+
+// runCommand runs the subcommand given on the command line
+// and returns the exit code
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 2 || args[0] != "config" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[1] {
+	case "validate":
+		path := defaultConfigPath
+		if len(args) > 2 {
+			path = args[2]
+		}
+		return validateConfig(path, stdout, stderr)
+	case "schema":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(config.Schema()); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+}
+
+// validateConfig prints any problems in the config at path,
+// valid configs are printed with the defaults applied
+func validateConfig(path string, stdout, stderr io.Writer) int {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	problems := config.Validate(raw)
+	for _, problem := range problems {
+		fmt.Fprintf(stderr, "%s:%d: %s: %s\n", path, problem.Line, problem.Severity, problem.Message)
+	}
+	if config.HasErrors(problems) {
+		return 1
+	}
+	var cfg types.PaulConfig
+	if err := cfg.LoadConfig(raw); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	cfg.SetDefaults()
+	effective, err := yaml.Marshal(cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if cfg.Extends != "" {
+		fmt.Fprintf(stdout, "# %s is merged in when paul loads this config\n", cfg.Extends)
+	}
+	fmt.Fprint(stdout, string(effective))
+	return 0
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		args     []string
+		exitCode int
+		stdout   string
+		stderr   string
+	}{
+		{
+			name:     "Test Valid Config Is Printed With Defaults",
+			config:   "maintainers:\n  - Spazzy757\nempty_description_check:\n  enabled: true\n",
+			args:     []string{"config", "validate"},
+			exitCode: 0,
+			stdout:   "empty_description_check:\n  enabled: true\n  message: There seems to be no description",
+		},
+		{
+			name:     "Test Extended Config Is Noted",
+			config:   "extends: .github\n",
+			args:     []string{"config", "validate"},
+			exitCode: 0,
+			stdout:   "# .github is merged in when paul loads this config\n",
+		},
+		{
+			name:     "Test Unknown Key Is Reported",
+			config:   "maintainers:\n  - Spazzy757\nmaintainer: []\n",
+			args:     []string{"config", "validate"},
+			exitCode: 1,
+			stderr:   "PAUL.yaml:3: error: field maintainer not found in type types.PaulConfig\n",
+		},
+		{
+			name:     "Test Type Error Is Reported",
+			config:   "pull_requests:\n  stale_time: soon\n",
+			args:     []string{"config", "validate"},
+			exitCode: 1,
+			stderr:   "PAUL.yaml:2: error: cannot unmarshal !!str `soon` into int\n",
+		},
+		{
+			name:     "Test Missing File Is Reported",
+			args:     []string{"config", "validate"},
+			exitCode: 1,
+			stderr:   "no such file or directory\n",
+		},
+		{
+			name:     "Test Schema Is Printed",
+			args:     []string{"config", "schema"},
+			exitCode: 0,
+			stdout:   `"maintainers"`,
+		},
+		{
+			name:     "Test Unknown Command Prints Usage",
+			args:     []string{"config", "check"},
+			exitCode: 2,
+			stderr:   usage,
+		},
+		{
+			name:     "Test Missing Subcommand Prints Usage",
+			args:     []string{"config"},
+			exitCode: 2,
+			stderr:   usage,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assertions := require.New(t)
+			args := tc.args
+			if len(args) > 1 && args[1] == "validate" {
+				path := filepath.Join(t.TempDir(), "PAUL.yaml")
+				if tc.config != "" {
+					assertions.NoError(os.WriteFile(path, []byte(tc.config), 0o600))
+				}
+				args = append(args, path)
+			}
+			var stdout, stderr bytes.Buffer
+			exitCode := runCommand(args, &stdout, &stderr)
+			assertions.Equal(tc.exitCode, exitCode)
+			if tc.stdout == "" {
+				assertions.Empty(stdout.String())
+			} else {
+				assertions.Contains(stdout.String(), tc.stdout)
+			}
+			if tc.stderr == "" {
+				assertions.Empty(stderr.String())
+			} else {
+				assertions.Contains(stderr.String(), tc.stderr)
+			}
+		})
+	}
+}
@@ -6,16 +6,24 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/Spazzy757/paul/pkg/config"
+	paulgithub "github.com/Spazzy757/paul/pkg/github"
 	"github.com/Spazzy757/paul/pkg/helpers"
+	"github.com/Spazzy757/paul/pkg/lock"
+	"github.com/Spazzy757/paul/pkg/queue"
 	"github.com/Spazzy757/paul/pkg/router"
 	"github.com/Spazzy757/paul/pkg/scheduler"
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
 )
 
+// drainTimeout is how long queued webhooks are given to finish on shutdown
+const drainTimeout = 20 * time.Second
+
 const startUpLog = `
 __________  _____   ____ ___.____     
 \______   \/  _  \ |    |   \    |    
@@ -26,9 +34,39 @@ __________  _____   ____ ___.____
 `
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	// Termination Handeling
 	termChan := make(chan os.Signal, 1)
 	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
+	// Cache repository configs between webhooks and scheduled jobs
+	cacheTTL, err := time.ParseDuration(helpers.GetEnv("CONFIG_CACHE_TTL", "5m"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Invalid CONFIG_CACHE_TTL")
+	}
+	config.EnableCache(cacheTTL)
+	// Set up labels and a starter config on newly installed repositories
+	if helpers.GetEnv("BOOTSTRAP_REPOSITORIES", "false") == "true" {
+		paulgithub.EnableBootstrap()
+	}
+	// Process webhooks in the background so GitHub gets a response in time
+	workers, err := strconv.Atoi(helpers.GetEnv("WEBHOOK_WORKERS", "4"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Invalid WEBHOOK_WORKERS")
+	}
+	queueSize, err := strconv.Atoi(helpers.GetEnv("WEBHOOK_QUEUE_SIZE", "100"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Invalid WEBHOOK_QUEUE_SIZE")
+	}
+	jobs := queue.New(workers, queueSize)
+	router.SetQueue(jobs)
 	// Get the routes
 	router := router.GetRouter()
 	// Set server configuration
@@ -59,7 +97,17 @@ func main() {
 			),
 		),
 	)
-	scheduler.AddSchedule(c)
+	locker, err := lock.NewFromEnv()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Lock Backend Setup Failed")
+	}
+	if err := scheduler.AddSchedule(c, locker); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Fatal("Scheduling Jobs Failed")
+	}
 	c.Start()
 
 	// Prints out ascii art
@@ -80,5 +128,13 @@ func main() {
 		}).Fatal("Graceful Shutdown Failed")
 	}
 	log.Info("Shutting Down Gracefully")
-	c.Stop()
+	<-c.Stop().Done()
+	// Finish webhooks that have already been accepted
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer drainCancel()
+	if err := jobs.Shutdown(drainCtx); err != nil {
+		log.WithFields(log.Fields{
+			"error": err.Error(),
+		}).Error("Draining Webhook Queue Failed")
+	}
 }
@@ -0,0 +1,33 @@
+package changes
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// recorderKey is the context key for the Recorder of the running work
+type recorderKey struct{}
+
+// Recorder notes if work has changed something on Github, e.g. commented or
+// labelled, so it isn't repeated
+type Recorder struct {
+	changed int32
+}
+
+// NewContext returns a context that records changes made with it
+func NewContext(parent context.Context) (context.Context, *Recorder) {
+	recorder := &Recorder{}
+	return context.WithValue(parent, recorderKey{}, recorder), recorder
+}
+
+// Record marks the work running with ctx as having changed something
+func Record(ctx context.Context) {
+	if recorder, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		atomic.StoreInt32(&recorder.changed, 1)
+	}
+}
+
+// Changed returns true once a change has been recorded
+func (r *Recorder) Changed() bool {
+	return atomic.LoadInt32(&r.changed) != 0
+}
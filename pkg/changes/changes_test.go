@@ -0,0 +1,20 @@
+package changes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("Test Change Is Recorded", func(t *testing.T) {
+		ctx, recorder := NewContext(context.Background())
+		assert.Equal(t, false, recorder.Changed())
+		Record(ctx)
+		assert.Equal(t, true, recorder.Changed())
+	})
+	t.Run("Test Recording Without A Recorder Does Nothing", func(t *testing.T) {
+		Record(context.Background())
+	})
+}
@@ -9,13 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"path"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Spazzy757/paul/pkg/changes"
+	"github.com/Spazzy757/paul/pkg/helpers"
 	"github.com/google/go-github/v49/github"
 	"github.com/lestrrat-go/jwx/jwa"
 	"github.com/lestrrat-go/jwx/jwk"
@@ -27,6 +30,12 @@ const (
 	secretKeyFile  = "paul-secret-key"
 	privateKeyFile = "paul-private-key"
 	githubBaseUrl  = "https://api.github.com"
+	// jwtExpiry is how long a signed JWT is valid for, Github allows up to 10 minutes
+	jwtExpiry = 9 * time.Minute
+	// jwtClockDrift is how far the issued at time is backdated
+	jwtClockDrift = time.Minute
+	// tokenExpiryMargin is how long before a token expires that it is refreshed
+	tokenExpiryMargin = time.Minute
 )
 
 // JWTAuth token issued by Github in response to signed JWT Token
@@ -48,78 +57,130 @@ type authClient struct {
 	Ctx     context.Context
 }
 
+// installationTokens caches a token source for each installation
+// so tokens are reused until they are about to expire
+var (
+	installationTokensMu sync.Mutex
+	installationTokens   = map[int64]oauth2.TokenSource{}
+)
+
 // GetInstallationClient returns an authorized Github Client for an installation
 func GetInstallationClient(installationID int64) (*github.Client, error) {
 	ctx := context.Background()
-	cfg, err := newConfig()
+	_, err := newConfig()
 	if err != nil {
 		return &github.Client{}, err
 	}
-	aClient := &authClient{
-		BaseUrl: githubBaseUrl,
-		Client:  http.DefaultClient,
-	}
-	token, err := getAccessToken(aClient, cfg, installationID)
+	tokenURL, err := getTokenBaseURL()
 	if err != nil {
 		return &github.Client{}, err
 	}
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
+	aClient := &authClient{
+		BaseUrl: tokenURL,
+		Client:  http.DefaultClient,
+	}
+	tc := oauth2.NewClient(ctx, getInstallationTokenSource(aClient, installationID))
 
-	client := github.NewClient(tc)
-	return client, err
+	return newGithubClient(tc)
 }
 
 // GetClient returns an authorized Github Client
 func GetClient() (*github.Client, error) {
 	ctx := context.Background()
-	cfg, err := newConfig()
+	// Sign a token up front so missing config or an invalid key
+	// is reported straight away
+	ts := &appTokenSource{}
+	token, err := ts.Token()
 	if err != nil {
 		return &github.Client{}, err
 	}
-	signed, err := getSignedToken(cfg.ApplicationID, cfg.PrivateKey)
+	tc := oauth2.NewClient(ctx, oauth2.ReuseTokenSource(token, ts))
+	return newGithubClient(tc)
+}
+
+// newGithubClient returns a client for github.com, or for a Github Enterprise
+// Server when GITHUB_API_URL is set. GITHUB_UPLOAD_URL defaults to the root of
+// the API URLs host as uploads are served from /api/uploads/
+func newGithubClient(httpClient *http.Client) (*github.Client, error) {
+	if httpClient != nil {
+		httpClient.Transport = &changeRecorder{base: httpClient.Transport}
+	}
+	apiURL := helpers.GetEnv("GITHUB_API_URL", "")
+	if apiURL == "" {
+		return github.NewClient(httpClient), nil
+	}
+	parsedAPIURL, err := url.Parse(apiURL)
 	if err != nil {
-		return &github.Client{}, err
+		return &github.Client{}, fmt.Errorf("invalid Github Enterprise URL: %s", err)
 	}
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{
-			AccessToken: signed,
-			TokenType:   "Bearer",
-		},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	client := github.NewClient(tc)
-	return client, err
+	serverURL := url.URL{Scheme: parsedAPIURL.Scheme, Host: parsedAPIURL.Host, Path: "/"}
+	uploadURL := helpers.GetEnv("GITHUB_UPLOAD_URL", serverURL.String())
+	client, err := github.NewEnterpriseClient(apiURL, uploadURL, httpClient)
+	if err != nil {
+		return &github.Client{}, fmt.Errorf("invalid Github Enterprise URL: %s", err)
+	}
+	return client, nil
+}
+
+// changeRecorder records successful requests that change something on Github
+// against the context of the request, so work that fails later on isn't repeated
+type changeRecorder struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *changeRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := c.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err == nil &&
+		req.Method != http.MethodGet &&
+		req.Method != http.MethodHead &&
+		resp.StatusCode < http.StatusBadRequest {
+		changes.Record(req.Context())
+	}
+	return resp, err
 }
 
-// NewConfig populates configuration from known-locations and gives
+// getTokenBaseURL returns the URL installation access tokens are requested from
+// this is the API URL unless GITHUB_APP_TOKEN_URL is set
+func getTokenBaseURL() (string, error) {
+	if tokenURL := helpers.GetEnv("GITHUB_APP_TOKEN_URL", ""); tokenURL != "" {
+		return strings.TrimSuffix(tokenURL, "/"), nil
+	}
+	if helpers.GetEnv("GITHUB_API_URL", "") == "" {
+		return githubBaseUrl, nil
+	}
+	client, err := newGithubClient(nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(client.BaseURL.String(), "/"), nil
+}
+
+// NewConfig populates configuration from the secret provider and gives
 // an error if configuration is missing from disk or environmental variables
 func newConfig() (config, error) {
 	config := config{}
 
-	keyPath, pathErr := getSecretPath()
-	if pathErr != nil {
-		return config, pathErr
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return config, err
 	}
 
-	secretKeyBytes, readErr := os.ReadFile(path.Join(keyPath, secretKeyFile))
-
-	if readErr != nil {
-		msg := fmt.Errorf("unable to read GitHub symmetrical secret: %s, error: %s",
-			keyPath+secretKeyFile, readErr)
-		return config, msg
+	secretKeyBytes, err := provider.GetSecret(secretKeyFile)
+	if err != nil {
+		return config, fmt.Errorf("unable to read GitHub symmetrical secret: %w", err)
 	}
 
 	secretKeyBytes = getFirstLine(secretKeyBytes)
 	config.SecretKey = string(secretKeyBytes)
 
-	privateKeyPath := path.Join(keyPath, privateKeyFile)
-
-	keyBytes, err := os.ReadFile(privateKeyPath)
+	keyBytes, err := provider.GetSecret(privateKeyFile)
 	if err != nil {
-		return config, fmt.Errorf("unable to read private key path: %s, error: %s", privateKeyPath, err)
+		return config, fmt.Errorf("unable to read private key: %w", err)
 	}
 
 	config.PrivateKey = string(keyBytes)
@@ -133,6 +194,16 @@ func newConfig() (config, error) {
 	return config, nil
 }
 
+// ApplicationID returns the ID of the Github App Paul runs as,
+// 0 when APPLICATION_ID isn't set
+func ApplicationID() int64 {
+	id, err := strconv.ParseInt(os.Getenv("APPLICATION_ID"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
 func getSecretPath() (string, error) {
 	secretPath := os.Getenv("SECRET_PATH")
 
@@ -151,22 +222,82 @@ func getFirstLine(secret []byte) []byte {
 	return secret
 }
 
-// GetAccessToken returns a Github OAuth Token
-func getAccessToken(client *authClient, config config, installationID int64) (string, error) {
-	token := os.Getenv("PERSONAL_ACCESS_TOKEN")
-	if len(token) == 0 {
-		installationToken, tokenErr := makeAccessTokenForInstallation(
-			client,
-			config.ApplicationID,
-			installationID,
-			config.PrivateKey,
-		)
-		if tokenErr != nil {
-			return "", tokenErr
-		}
-		token = installationToken
+// ForgetInstallation drops the cached access token of an installation,
+// used when the app is uninstalled
+func ForgetInstallation(installationID int64) {
+	installationTokensMu.Lock()
+	defer installationTokensMu.Unlock()
+	delete(installationTokens, installationID)
+}
+
+// getInstallationTokenSource returns the cached token source for an installation
+// a PERSONAL_ACCESS_TOKEN is used instead of installation tokens when set
+func getInstallationTokenSource(client *authClient, installationID int64) oauth2.TokenSource {
+	if token := os.Getenv("PERSONAL_ACCESS_TOKEN"); len(token) != 0 {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	}
+	installationTokensMu.Lock()
+	defer installationTokensMu.Unlock()
+	ts, ok := installationTokens[installationID]
+	if !ok {
+		ts = oauth2.ReuseTokenSource(nil, &installationTokenSource{
+			client:         client,
+			installationID: installationID,
+		})
+		installationTokens[installationID] = ts
 	}
-	return token, nil
+	return ts
+}
+
+// installationTokenSource makes a new installation access token each time
+// it is called, it should be wrapped with oauth2.ReuseTokenSource
+type installationTokenSource struct {
+	client         *authClient
+	installationID int64
+}
+
+// Token makes an installation access token which expires slightly before
+// Github expires it so it is refreshed before requests start failing
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	// Config is read for each token so rotated keys are picked up
+	cfg, err := newConfig()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := makeAccessTokenForInstallation(
+		s.client,
+		cfg.ApplicationID,
+		s.installationID,
+		cfg.PrivateKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: auth.Token,
+		TokenType:   "token",
+		Expiry:      auth.ExpiresAt.Add(-tokenExpiryMargin),
+	}, nil
+}
+
+// appTokenSource signs a JWT to authenticate as the Github App
+type appTokenSource struct{}
+
+// Token signs a new JWT
+func (s *appTokenSource) Token() (*oauth2.Token, error) {
+	cfg, err := newConfig()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := getSignedToken(cfg.ApplicationID, cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		Expiry:      time.Now().Add(jwtExpiry - tokenExpiryMargin),
+	}, nil
 }
 
 // MakeAccessTokenForInstallation makes an access token for an installation / private key
@@ -175,10 +306,11 @@ func makeAccessTokenForInstallation(
 	appID string,
 	installation int64,
 	privateKey string,
-) (string, error) {
+) (JWTAuth, error) {
+	jwtAuth := JWTAuth{}
 	signed, err := getSignedToken(appID, privateKey)
 	if err != nil {
-		return "", err
+		return jwtAuth, err
 	}
 	url := fmt.Sprintf(
 		"%v/app/installations/%d/access_tokens",
@@ -187,7 +319,7 @@ func makeAccessTokenForInstallation(
 	)
 	req, err := http.NewRequest(http.MethodPost, url, nil)
 	if err != nil {
-		return "", err
+		return jwtAuth, err
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", signed))
 	req.Header.Add("Accept", "application/vnd.github.machine-man-preview+json")
@@ -195,18 +327,26 @@ func makeAccessTokenForInstallation(
 	res, err := c.Client.Do(req)
 
 	if err != nil {
-		return "", fmt.Errorf("error getting Access token %v", err)
+		return jwtAuth, fmt.Errorf("error getting Access token %v", err)
 	}
 
 	defer res.Body.Close()
 	bytesOut, readErr := io.ReadAll(res.Body)
 	if readErr != nil {
-		return "", readErr
+		return jwtAuth, readErr
 	}
 
-	jwtAuth := JWTAuth{}
-	jsonErr := json.Unmarshal(bytesOut, &jwtAuth)
-	return jwtAuth.Token, jsonErr
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return jwtAuth, newAccessTokenError(installation, res.StatusCode, bytesOut)
+	}
+
+	if jsonErr := json.Unmarshal(bytesOut, &jwtAuth); jsonErr != nil {
+		return jwtAuth, jsonErr
+	}
+	if jwtAuth.Token == "" {
+		return jwtAuth, newAccessTokenError(installation, res.StatusCode, bytesOut)
+	}
+	return jwtAuth, nil
 }
 
 // getSignedToken Returns a signed JWT Token
@@ -227,13 +367,13 @@ func getSignedToken(appID string, privateKey string) (string, error) {
 	now := time.Now()
 	// Ignore errors of setting claims
 	_ = token.Set(jwt.IssuerKey, appID)
-	_ = token.Set(jwt.IssuedAtKey, now.Unix())
-	_ = token.Set(jwt.ExpirationKey, now.Add(time.Minute*9).Unix())
+	// Backdated so Github accepts it when our clock is slightly ahead
+	_ = token.Set(jwt.IssuedAtKey, now.Add(-jwtClockDrift).Unix())
+	_ = token.Set(jwt.ExpirationKey, now.Add(jwtExpiry).Unix())
 
 	// Sign the token and generate a payload
 	signed, err := jwt.Sign(token, jwa.RS256, realKey)
 	if err != nil {
-		log.Printf("HERE")
 		return "", err
 	}
 
@@ -241,6 +381,7 @@ func getSignedToken(appID string, privateKey string) (string, error) {
 }
 
 // BytesToPrivateKey bytes to private key
+// Keys can be PKCS#1, as downloaded from Github, or PKCS#8
 func bytesToPrivateKey(priv []byte) (*rsa.PrivateKey, error) {
 	block, _ := pem.Decode(priv)
 	if block == nil {
@@ -248,5 +389,16 @@ func bytesToPrivateKey(priv []byte) (*rsa.PrivateKey, error) {
 	}
 	b := block.Bytes
 	key, err := x509.ParsePKCS1PrivateKey(b)
-	return key, err
+	if err == nil {
+		return key, nil
+	}
+	pkcs8Key, pkcs8Err := x509.ParsePKCS8PrivateKey(b)
+	if pkcs8Err != nil {
+		return &rsa.PrivateKey{}, fmt.Errorf("unable to parse private key as PKCS#1 or PKCS#8: %s", err)
+	}
+	rsaKey, ok := pkcs8Key.(*rsa.PrivateKey)
+	if !ok {
+		return &rsa.PrivateKey{}, errors.New("private key must be an RSA key")
+	}
+	return rsaKey, nil
 }
@@ -1,18 +1,25 @@
 package client
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Spazzy757/paul/pkg/changes"
 	"github.com/google/go-github/v49/github"
 	"github.com/lestrrat-go/jwx/jwa"
 	"github.com/lestrrat-go/jwx/jwt"
@@ -30,7 +37,7 @@ func TestNewConfigNoSecretPath(t *testing.T) {
 	}
 
 	want := "SECRET_PATH env-var not set"
-	if err.Error() != want {
+	if !errors.Is(err, ErrSecretNotFound) || !strings.Contains(err.Error(), want) {
 		t.Errorf("want %q, got %q", want, err.Error())
 		t.Fail()
 	}
@@ -214,18 +221,82 @@ func TestAccessToken(t *testing.T) {
 	t.Run("Test set Environment Returns Personal Token token", func(t *testing.T) {
 		os.Setenv("PERSONAL_ACCESS_TOKEN", "123456789")
 
-		cfg, _ := newConfig()
-
-		token, _ := getAccessToken(aClient, cfg, 1)
-		assert.Equal(t, token, "123456789")
+		token, _ := getInstallationTokenSource(aClient, 1).Token()
+		assert.Equal(t, token.AccessToken, "123456789")
 	})
 	t.Run("Test Unset Environment Err", func(t *testing.T) {
 		os.Unsetenv("PERSONAL_ACCESS_TOKEN")
 
-		cfg, _ := newConfig()
+		token, err := getInstallationTokenSource(aClient, 1).Token()
+		assert.Nil(t, token)
+		assert.NotEqual(t, nil, err)
+	})
+}
 
-		token, _ := getAccessToken(aClient, cfg, 1)
-		assert.Equal(t, token, "")
+func TestInstallationTokenSource(t *testing.T) {
+	serverUrl, mux, teardown := ServerMock()
+	defer teardown()
+	aClient := &authClient{
+		BaseUrl: serverUrl,
+		Client:  http.DefaultClient,
+	}
+	tmpDir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	keyBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	_ = os.WriteFile(path.Join(tmpDir, "paul-private-key"), keyBytes, 0600)
+	_ = os.WriteFile(path.Join(tmpDir, "paul-secret-key"), []byte("secret"), 0600)
+	os.Setenv("SECRET_PATH", tmpDir)
+	os.Setenv("APPLICATION_ID", "321")
+	os.Unsetenv("PERSONAL_ACCESS_TOKEN")
+
+	calls := map[int64]int{}
+	expiresIn := map[int64]time.Duration{
+		10: time.Hour,
+		11: 30 * time.Second,
+	}
+	for id := range expiresIn {
+		id := id
+		mux.HandleFunc(
+			fmt.Sprintf("/app/installations/%d/access_tokens", id),
+			func(w http.ResponseWriter, r *http.Request) {
+				calls[id]++
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(
+					w,
+					`{"token":"token-%d","expires_at":"%s"}`,
+					calls[id],
+					time.Now().Add(expiresIn[id]).Format(time.RFC3339),
+				)
+			},
+		)
+	}
+	t.Run("Test Token Is Reused Until It Expires", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			token, err := getInstallationTokenSource(aClient, 10).Token()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "token-1", token.AccessToken)
+		}
+		assert.Equal(t, 1, calls[10])
+	})
+	t.Run("Test Token Is Refreshed Before It Expires", func(t *testing.T) {
+		first, err := getInstallationTokenSource(aClient, 11).Token()
+		assert.Equal(t, nil, err)
+		second, err := getInstallationTokenSource(aClient, 11).Token()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "token-1", first.AccessToken)
+		assert.Equal(t, "token-2", second.AccessToken)
+		assert.Equal(t, 2, calls[11])
+	})
+	t.Run("Test Forgotten Installation Gets A New Token", func(t *testing.T) {
+		ForgetInstallation(10)
+		token, err := getInstallationTokenSource(aClient, 10).Token()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "token-2", token.AccessToken)
+		assert.Equal(t, 2, calls[10])
 	})
 }
 
@@ -273,7 +344,7 @@ func TestMakeAccessTokenForInstallation(t *testing.T) {
 		)
 		token, err := makeAccessTokenForInstallation(aClient, "123", 645, string(signingKey))
 		assert.Equal(t, nil, err)
-		assert.Equal(t, "123456", token)
+		assert.Equal(t, "123456", token.Token)
 	})
 	t.Run("Test Getting Token For Installation", func(t *testing.T) {
 		aClient := &authClient{
@@ -358,3 +429,213 @@ func TestGetSignedToken(t *testing.T) {
 		assert.NotEqual(t, err, nil)
 	})
 }
+
+func TestNewGithubClient(t *testing.T) {
+	t.Run("Test Defaults To Github", func(t *testing.T) {
+		os.Unsetenv("GITHUB_API_URL")
+		os.Unsetenv("GITHUB_UPLOAD_URL")
+		os.Unsetenv("GITHUB_APP_TOKEN_URL")
+		client, err := newGithubClient(nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://api.github.com/", client.BaseURL.String())
+		tokenURL, err := getTokenBaseURL()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, githubBaseUrl, tokenURL)
+	})
+	t.Run("Test Enterprise Server", func(t *testing.T) {
+		os.Setenv("GITHUB_API_URL", "https://github.example.com")
+		defer os.Unsetenv("GITHUB_API_URL")
+		client, err := newGithubClient(nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+		assert.Equal(t, "https://github.example.com/api/uploads/", client.UploadURL.String())
+		tokenURL, err := getTokenBaseURL()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://github.example.com/api/v3", tokenURL)
+	})
+	t.Run("Test Enterprise Server API Path Is Not Used For Uploads", func(t *testing.T) {
+		os.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3/")
+		defer os.Unsetenv("GITHUB_API_URL")
+		client, err := newGithubClient(nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+		assert.Equal(t, "https://github.example.com/api/uploads/", client.UploadURL.String())
+	})
+	t.Run("Test Enterprise Server With Upload And Token URLs", func(t *testing.T) {
+		os.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3/")
+		os.Setenv("GITHUB_UPLOAD_URL", "https://uploads.example.com/")
+		os.Setenv("GITHUB_APP_TOKEN_URL", "https://apps.example.com/")
+		defer os.Unsetenv("GITHUB_API_URL")
+		defer os.Unsetenv("GITHUB_UPLOAD_URL")
+		defer os.Unsetenv("GITHUB_APP_TOKEN_URL")
+		client, err := newGithubClient(nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+		assert.Equal(t, "https://uploads.example.com/api/uploads/", client.UploadURL.String())
+		tokenURL, err := getTokenBaseURL()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "https://apps.example.com", tokenURL)
+	})
+	t.Run("Test Invalid Enterprise URL", func(t *testing.T) {
+		os.Setenv("GITHUB_API_URL", "://github.example.com")
+		defer os.Unsetenv("GITHUB_API_URL")
+		_, err := newGithubClient(nil)
+		assert.NotEqual(t, nil, err)
+		_, err = getTokenBaseURL()
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func TestChangeRecorder(t *testing.T) {
+	serverURL, mux, teardown := ServerMock()
+	defer teardown()
+	mux.HandleFunc("/api/v3/repos/Spazzy757/paul/issues/1/labels", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/api/v3/repos/Spazzy757/paul/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	os.Setenv("GITHUB_API_URL", serverURL+"/")
+	defer os.Unsetenv("GITHUB_API_URL")
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		expected bool
+	}{
+		{name: "Test Reads Are Not Recorded", method: "GET", path: "repos/Spazzy757/paul/issues/1/labels"},
+		{name: "Test Changes Are Recorded", method: "POST", path: "repos/Spazzy757/paul/issues/1/labels", expected: true},
+		{name: "Test Failed Changes Are Not Recorded", method: "POST", path: "repos/Spazzy757/paul/issues/1/comments"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := newGithubClient(&http.Client{})
+			assert.Equal(t, nil, err)
+			ctx, recorder := changes.NewContext(context.Background())
+			req, err := client.NewRequest(tc.method, tc.path, nil)
+			assert.Equal(t, nil, err)
+			_, _ = client.Do(ctx, req, nil)
+			assert.Equal(t, tc.expected, recorder.Changed())
+		})
+	}
+}
+
+func TestMakeAccessTokenForInstallationErrors(t *testing.T) {
+	serverUrl, mux, teardown := ServerMock()
+	defer teardown()
+	aClient := &authClient{
+		BaseUrl: serverUrl,
+		Client:  http.DefaultClient,
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	signingKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	var errorTests = []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   error
+	}{
+		{
+			name:       "Bad Credentials",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"message":"A JSON web token could not be decoded"}`,
+			expected:   ErrBadCredentials,
+		},
+		{
+			name:       "Clock Skew",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"message":"'Issued at' claim ('iat') must be an Integer representing a time in the past"}`,
+			expected:   ErrClockSkew,
+		},
+		{
+			name:       "Installation Suspended",
+			statusCode: http.StatusForbidden,
+			body:       `{"message":"This installation has been suspended"}`,
+			expected:   ErrInstallationSuspended,
+		},
+		{
+			name:       "Installation Not Found",
+			statusCode: http.StatusNotFound,
+			body:       `{"message":"Not Found"}`,
+			expected:   ErrInstallationNotFound,
+		},
+		{
+			name:       "Server Error",
+			statusCode: http.StatusBadGateway,
+			body:       `bad gateway`,
+			expected:   ErrUnexpectedResponse,
+		},
+		{
+			name:       "Empty Token",
+			statusCode: http.StatusCreated,
+			body:       `{}`,
+			expected:   ErrUnexpectedResponse,
+		},
+	}
+	for i, test := range errorTests {
+		test := test
+		installation := int64(700 + i)
+		mux.HandleFunc(
+			fmt.Sprintf("/app/installations/%d/access_tokens", installation),
+			func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.statusCode)
+				fmt.Fprint(w, test.body)
+			},
+		)
+		t.Run(test.name, func(t *testing.T) {
+			_, err := makeAccessTokenForInstallation(aClient, "123", installation, string(signingKey))
+			assert.True(t, errors.Is(err, test.expected))
+			tokenErr := &AccessTokenError{}
+			assert.True(t, errors.As(err, &tokenErr))
+			assert.Equal(t, test.statusCode, tokenErr.StatusCode)
+			assert.Equal(t, installation, tokenErr.InstallationID)
+		})
+	}
+}
+
+func TestBytesToPrivateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	t.Run("Test PKCS1 Key", func(t *testing.T) {
+		keyBytes := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+		parsed, err := bytesToPrivateKey(keyBytes)
+		assert.Equal(t, nil, err)
+		assert.True(t, key.Equal(parsed))
+	})
+	t.Run("Test PKCS8 Key", func(t *testing.T) {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.Equal(t, nil, err)
+		keyBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		parsed, err := bytesToPrivateKey(keyBytes)
+		assert.Equal(t, nil, err)
+		assert.True(t, key.Equal(parsed))
+	})
+	t.Run("Test Non RSA PKCS8 Key", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.Equal(t, nil, err)
+		der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+		assert.Equal(t, nil, err)
+		keyBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		_, err = bytesToPrivateKey(keyBytes)
+		assert.NotEqual(t, nil, err)
+	})
+	t.Run("Test Signed Token Is Backdated", func(t *testing.T) {
+		keyBytes := pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+		tokenString, err := getSignedToken("123", string(keyBytes))
+		assert.Equal(t, nil, err)
+		token, err := jwt.Parse([]byte(tokenString), jwt.WithVerify(jwa.RS256, &key.PublicKey))
+		assert.Equal(t, nil, err)
+		assert.True(t, token.IssuedAt().Before(time.Now().Add(-jwtClockDrift+time.Second)))
+		assert.True(t, token.Expiration().Sub(token.IssuedAt()) <= 10*time.Minute)
+	})
+}
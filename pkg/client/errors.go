@@ -0,0 +1,81 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrBadCredentials is returned when Github rejects the app's JWT,
+	// usually because APPLICATION_ID or the private key is wrong
+	ErrBadCredentials = errors.New("github rejected the app credentials, check APPLICATION_ID and the private key")
+	// ErrClockSkew is returned when Github rejects the times in the app's JWT
+	ErrClockSkew = errors.New("github rejected the JWT issued at or expiry time, check the server's clock")
+	// ErrInstallationNotFound is returned when the installation does not exist
+	// or does not belong to this app
+	ErrInstallationNotFound = errors.New("installation not found")
+	// ErrInstallationSuspended is returned when the installation has been suspended
+	ErrInstallationSuspended = errors.New("installation is suspended")
+	// ErrUnexpectedResponse is returned for any other failed token request
+	ErrUnexpectedResponse = errors.New("unexpected response from github")
+)
+
+// AccessTokenError is returned when Github does not issue an installation
+// access token, use errors.Is to check the reason
+type AccessTokenError struct {
+	InstallationID int64
+	StatusCode     int
+	Message        string
+	Err            error
+}
+
+func (e *AccessTokenError) Error() string {
+	return fmt.Sprintf(
+		"unable to get access token for installation %d: %s (status code: %d, message: %q)",
+		e.InstallationID,
+		e.Err,
+		e.StatusCode,
+		e.Message,
+	)
+}
+
+func (e *AccessTokenError) Unwrap() error {
+	return e.Err
+}
+
+// newAccessTokenError classifies a failed access token response
+func newAccessTokenError(installationID int64, statusCode int, body []byte) *AccessTokenError {
+	githubErr := struct {
+		Message string `json:"message"`
+	}{}
+	_ = json.Unmarshal(body, &githubErr)
+	message := strings.ToLower(githubErr.Message)
+
+	err := ErrUnexpectedResponse
+	switch statusCode {
+	case http.StatusUnauthorized:
+		err = ErrBadCredentials
+		// i.e "'Issued at' claim ('iat') must be an Integer representing a time in the past"
+		if strings.Contains(message, "'iat'") ||
+			strings.Contains(message, "'exp'") ||
+			strings.Contains(message, "issued at") ||
+			strings.Contains(message, "expiration time") {
+			err = ErrClockSkew
+		}
+	case http.StatusForbidden:
+		if strings.Contains(message, "suspended") {
+			err = ErrInstallationSuspended
+		}
+	case http.StatusNotFound:
+		err = ErrInstallationNotFound
+	}
+	return &AccessTokenError{
+		InstallationID: installationID,
+		StatusCode:     statusCode,
+		Message:        githubErr.Message,
+		Err:            err,
+	}
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSecretNotFound is returned when a provider does not have a secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// envSecrets are the env-vars each secret can be set with,
+// env-vars ending in _BASE64 are decoded
+var envSecrets = map[string][]string{
+	secretKeyFile:  {"SECRET_KEY"},
+	privateKeyFile: {"PRIVATE_KEY", "PRIVATE_KEY_BASE64"},
+}
+
+// SecretProvider looks up the webhook secret and private key by name
+type SecretProvider interface {
+	GetSecret(name string) ([]byte, error)
+}
+
+/*
+NewSecretProvider returns the provider set by SECRET_PROVIDER:
+  - "env" reads SECRET_KEY and PRIVATE_KEY or PRIVATE_KEY_BASE64
+  - "file" reads paul-secret-key and paul-private-key in SECRET_PATH
+  - by default env-vars are used, falling back to files if SECRET_PATH is set
+*/
+func NewSecretProvider() (SecretProvider, error) {
+	switch provider := os.Getenv("SECRET_PROVIDER"); provider {
+	case "env":
+		return &EnvSecretProvider{}, nil
+	case "file":
+		secretPath, err := getSecretPath()
+		if err != nil {
+			return nil, err
+		}
+		return &FileSecretProvider{Dir: secretPath}, nil
+	case "":
+		providers := ChainSecretProvider{&EnvSecretProvider{}}
+		if secretPath, err := getSecretPath(); err == nil {
+			providers = append(providers, &FileSecretProvider{Dir: secretPath})
+		}
+		return providers, nil
+	default:
+		return nil, fmt.Errorf("unknown SECRET_PROVIDER: %s", provider)
+	}
+}
+
+// GetWebhookSecret returns the secret webhooks are signed with
+func GetWebhookSecret() ([]byte, error) {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := provider.GetSecret(secretKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return getFirstLine(secret), nil
+}
+
+// EnvSecretProvider reads secrets from env-vars
+type EnvSecretProvider struct{}
+
+// GetSecret returns the first env-var set for the secret
+func (p *EnvSecretProvider) GetSecret(name string) ([]byte, error) {
+	for _, env := range envSecrets[name] {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if strings.HasSuffix(env, "_BASE64") {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode %s: %s", env, err)
+			}
+			return decoded, nil
+		}
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("%w: %s is not set", ErrSecretNotFound, strings.Join(envSecrets[name], " or "))
+}
+
+type cachedSecret struct {
+	modTime time.Time
+	size    int64
+	value   []byte
+}
+
+// fileSecrets caches secret files until they change on disk
+var (
+	fileSecretsMu sync.Mutex
+	fileSecrets   = map[string]cachedSecret{}
+)
+
+// FileSecretProvider reads secrets from files in Dir named after the secret,
+// i.e a mounted Kubernetes secret. Files are read again when they change
+// so rotated secrets are picked up without a restart
+type FileSecretProvider struct {
+	Dir string
+}
+
+// GetSecret returns the contents of the secrets file
+func (p *FileSecretProvider) GetSecret(name string) ([]byte, error) {
+	secretPath := path.Join(p.Dir, name)
+	info, err := os.Stat(secretPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s does not exist", ErrSecretNotFound, secretPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret: %s, error: %s", secretPath, err)
+	}
+
+	fileSecretsMu.Lock()
+	defer fileSecretsMu.Unlock()
+	cached, ok := fileSecrets[secretPath]
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.value, nil
+	}
+	value, err := os.ReadFile(secretPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read secret: %s, error: %s", secretPath, err)
+	}
+	fileSecrets[secretPath] = cachedSecret{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		value:   value,
+	}
+	return value, nil
+}
+
+// ChainSecretProvider returns the secret from the first provider that has it
+type ChainSecretProvider []SecretProvider
+
+// GetSecret tries each provider in order
+func (c ChainSecretProvider) GetSecret(name string) ([]byte, error) {
+	err := fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	for _, provider := range c {
+		var value []byte
+		value, err = provider.GetSecret(name)
+		if !errors.Is(err, ErrSecretNotFound) {
+			return value, err
+		}
+	}
+	if os.Getenv("SECRET_PATH") == "" {
+		return nil, fmt.Errorf("%w and SECRET_PATH env-var not set", err)
+	}
+	return nil, err
+}
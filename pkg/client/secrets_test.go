@@ -0,0 +1,105 @@
+package client
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSecretProvider(t *testing.T) {
+	provider := &EnvSecretProvider{}
+	t.Run("Test Reads Webhook Secret", func(t *testing.T) {
+		t.Setenv("SECRET_KEY", "secret")
+		secret, err := provider.GetSecret(secretKeyFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", string(secret))
+	})
+	t.Run("Test Reads Base64 Private Key", func(t *testing.T) {
+		t.Setenv("PRIVATE_KEY", "")
+		t.Setenv("PRIVATE_KEY_BASE64", base64.StdEncoding.EncodeToString([]byte("private")))
+		secret, err := provider.GetSecret(privateKeyFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "private", string(secret))
+	})
+	t.Run("Test Invalid Base64 Private Key", func(t *testing.T) {
+		t.Setenv("PRIVATE_KEY", "")
+		t.Setenv("PRIVATE_KEY_BASE64", "not base64!")
+		_, err := provider.GetSecret(privateKeyFile)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrSecretNotFound))
+	})
+	t.Run("Test Missing Secret", func(t *testing.T) {
+		t.Setenv("SECRET_KEY", "")
+		_, err := provider.GetSecret(secretKeyFile)
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	provider := &FileSecretProvider{Dir: dir}
+	secretPath := path.Join(dir, secretKeyFile)
+	require.NoError(t, os.WriteFile(secretPath, []byte("first"), 0600))
+
+	t.Run("Test Reads Secret", func(t *testing.T) {
+		secret, err := provider.GetSecret(secretKeyFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "first", string(secret))
+	})
+	t.Run("Test Reads Rotated Secret", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(secretPath, []byte("second"), 0600))
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(secretPath, later, later))
+		secret, err := provider.GetSecret(secretKeyFile)
+		assert.NoError(t, err)
+		assert.Equal(t, "second", string(secret))
+	})
+	t.Run("Test Missing Secret", func(t *testing.T) {
+		_, err := provider.GetSecret(privateKeyFile)
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+}
+
+func TestNewSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, secretKeyFile), []byte("file-secret\n"), 0600))
+	t.Setenv("SECRET_PATH", dir)
+	t.Run("Test Env Overrides Files By Default", func(t *testing.T) {
+		t.Setenv("SECRET_PROVIDER", "")
+		t.Setenv("SECRET_KEY", "env-secret")
+		secret, err := GetWebhookSecret()
+		assert.NoError(t, err)
+		assert.Equal(t, "env-secret", string(secret))
+	})
+	t.Run("Test Falls Back To Files", func(t *testing.T) {
+		t.Setenv("SECRET_PROVIDER", "")
+		t.Setenv("SECRET_KEY", "")
+		secret, err := GetWebhookSecret()
+		assert.NoError(t, err)
+		assert.Equal(t, "file-secret", string(secret))
+	})
+	t.Run("Test File Provider", func(t *testing.T) {
+		t.Setenv("SECRET_PROVIDER", "file")
+		t.Setenv("SECRET_KEY", "env-secret")
+		secret, err := GetWebhookSecret()
+		assert.NoError(t, err)
+		assert.Equal(t, "file-secret", string(secret))
+	})
+	t.Run("Test Env Provider", func(t *testing.T) {
+		t.Setenv("SECRET_PROVIDER", "env")
+		t.Setenv("SECRET_KEY", "")
+		_, err := GetWebhookSecret()
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+	t.Run("Test Unknown Provider", func(t *testing.T) {
+		t.Setenv("SECRET_PROVIDER", "vault")
+		_, err := NewSecretProvider()
+		assert.Error(t, err)
+	})
+}
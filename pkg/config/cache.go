@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+// cache is only used once enabled so that every call to GetPaulConfig
+// reads the latest config by default
+var cache *Cache
+
+// Cache keeps the config of each repository along with the SHAs of the
+// repositories it was read from. Once the TTL has passed the SHAs are checked
+// with conditional requests and the config is only downloaded if one changed
+type Cache struct {
+	TTL     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	config    types.PaulConfig
+	sources   []cachedSource
+	expiresAt time.Time
+}
+
+// cachedSource is a repository a cached config was built from and the
+// SHA of the ref it was read at, the SHA is empty if the repository
+// didn't exist
+type cachedSource struct {
+	configSource
+	ref string
+	sha string
+}
+
+// NewCache returns an empty Cache
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		TTL:     ttl,
+		entries: map[string]*cacheEntry{},
+		now:     time.Now,
+	}
+}
+
+// EnableCache caches configs returned by GetPaulConfig for the ttl given,
+// a ttl of 0 disables the cache
+func EnableCache(ttl time.Duration) {
+	if ttl <= 0 {
+		cache = nil
+		return
+	}
+	cache = NewCache(ttl)
+}
+
+// InvalidateCache removes the config of a repository from the cache along
+// with the configs of any repositories that extend it
+func InvalidateCache(owner, repo string) {
+	if cache != nil {
+		cache.Invalidate(owner, repo)
+	}
+}
+
+// InvalidateOwner removes the configs of all an owners repositories from the cache
+func InvalidateOwner(owner string) {
+	if cache != nil {
+		cache.InvalidateOwner(owner)
+	}
+}
+
+// Invalidate removes a repository and any repositories built from its config
+func (c *Cache) Invalidate(owner, repo string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := repoKey(owner, repo)
+	for entryKey, entry := range c.entries {
+		for _, source := range entry.sources {
+			if repoKey(source.owner, source.repo) == key {
+				delete(c.entries, entryKey)
+				break
+			}
+		}
+	}
+}
+
+// InvalidateOwner removes every repository belonging to an owner
+func (c *Cache) InvalidateOwner(owner string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := strings.ToLower(owner) + "/"
+	for entryKey := range c.entries {
+		if strings.HasPrefix(entryKey, prefix) {
+			delete(c.entries, entryKey)
+		}
+	}
+}
+
+func (c *Cache) get(
+	ctx context.Context,
+	owner, repo, defaultBranch string,
+	client *github.Client,
+) (types.PaulConfig, error) {
+	key := repoKey(owner, repo)
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expiresAt) {
+		return entry.config, nil
+	}
+
+	if ok && sourcesUnchanged(ctx, client, entry.sources) {
+		c.set(key, entry.config, entry.sources)
+		return entry.config, nil
+	}
+	// The config is still fetched without a SHA but it isn't cached
+	// as there is no way to tell if it has changed
+	sha, err := sourceSHA(ctx, client, owner, repo, defaultBranch)
+	cacheable := err == nil && sha != ""
+
+	cfg, sources, err := loadPaulConfig(ctx, owner, repo, defaultBranch, client)
+	if err != nil || !cacheable {
+		return cfg, err
+	}
+	cached := []cachedSource{{configSource: sources[0], ref: defaultBranch, sha: sha}}
+	// Extended configs are read from the default branch of their repository
+	for _, source := range sources[1:] {
+		sha, err := sourceSHA(ctx, client, source.owner, source.repo, "HEAD")
+		if err != nil {
+			return cfg, nil
+		}
+		cached = append(cached, cachedSource{configSource: source, ref: "HEAD", sha: sha})
+	}
+	c.set(key, cfg, cached)
+	return cfg, nil
+}
+
+// sourcesUnchanged checks that none of the repositories a config was
+// built from have new commits. Not modified responses don't count
+// against the rate limit
+func sourcesUnchanged(ctx context.Context, client *github.Client, sources []cachedSource) bool {
+	for _, source := range sources {
+		_, resp, _ := client.Repositories.GetCommitSHA1(ctx, source.owner, source.repo, source.ref, source.sha)
+		if resp == nil {
+			return false
+		}
+		if resp.StatusCode == http.StatusNotModified {
+			continue
+		}
+		// The repository still doesn't exist
+		if source.sha == "" && resp.StatusCode == http.StatusNotFound {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// sourceSHA returns the SHA of a ref, an empty SHA is returned
+// if the repository or ref doesn't exist
+func sourceSHA(ctx context.Context, client *github.Client, owner, repo, ref string) (string, error) {
+	sha, resp, err := client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	return sha, err
+}
+
+func (c *Cache) set(key string, cfg types.PaulConfig, sources []cachedSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = &cacheEntry{
+		config:    cfg,
+		sources:   sources,
+		expiresAt: c.now().Add(c.TTL),
+	}
+}
+
+// repoKey is case insensitive as GitHub owners and repositories are
+func repoKey(owner, repo string) string {
+	return strings.ToLower(owner + "/" + repo)
+}
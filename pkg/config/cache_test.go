@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigCache(t *testing.T) {
+	assertions := require.New(t)
+	mClient, mux, serverURL, teardown := test.GetMockClient()
+	defer teardown()
+
+	sha := "abc"
+	downloads := 0
+	shaChecks := 0
+	staleTime := 5
+	mux.HandleFunc("/repos/Spazzy757/paul/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		shaChecks++
+		if r.Header.Get("If-None-Match") == `"`+sha+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, sha)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/contents/.github", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{
+		    "type": "file",
+		    "name": "PAUL.yaml",
+		    "download_url": "`+serverURL+baseURLPath+`/download/PAUL.yaml"
+		}]`)
+	})
+	mux.HandleFunc("/download/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		fmt.Fprintf(w, "extends: .github\npull_requests:\n  stale_time: %d\n", staleTime)
+	})
+
+	now := time.Now()
+	EnableCache(time.Minute)
+	defer EnableCache(0)
+	cache.now = func() time.Time { return now }
+	getStaleTime := func() int {
+		cfg, err := GetPaulConfig(context.Background(), "Spazzy757", "paul", "main", mClient)
+		assertions.NoError(err)
+		return cfg.PullRequests.StaleTime
+	}
+
+	t.Run("Test Config Is Downloaded Once Within TTL", func(t *testing.T) {
+		assertions.Equal(5, getStaleTime())
+		staleTime = 10
+		assertions.Equal(5, getStaleTime())
+		assertions.Equal(1, downloads)
+		assertions.Equal(1, shaChecks)
+	})
+	t.Run("Test Unchanged SHA Reuses Config After TTL", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		assertions.Equal(5, getStaleTime())
+		assertions.Equal(1, downloads)
+		assertions.Equal(2, shaChecks)
+	})
+	t.Run("Test Changed SHA Downloads Config After TTL", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		sha = "def"
+		assertions.Equal(10, getStaleTime())
+		assertions.Equal(2, downloads)
+	})
+	t.Run("Test Invalidate Downloads Config", func(t *testing.T) {
+		staleTime = 15
+		InvalidateCache("spazzy757", "PAUL")
+		assertions.Equal(15, getStaleTime())
+		assertions.Equal(3, downloads)
+	})
+	t.Run("Test Invalidating Extended Config Downloads Config", func(t *testing.T) {
+		staleTime = 20
+		InvalidateCache("Spazzy757", ".github")
+		assertions.Equal(20, getStaleTime())
+		assertions.Equal(4, downloads)
+	})
+	t.Run("Test Invalidate Owner Downloads Config", func(t *testing.T) {
+		staleTime = 25
+		InvalidateOwner("Spazzy757")
+		assertions.Equal(25, getStaleTime())
+		assertions.Equal(5, downloads)
+	})
+}
+
+func TestConfigCacheExtendedChanges(t *testing.T) {
+	assertions := require.New(t)
+	mClient, mux, serverURL, teardown := test.GetMockClient()
+	defer teardown()
+
+	orgSHA := "abc"
+	orgStaleTime := 5
+	downloads := 0
+	mux.HandleFunc("/repos/Spazzy757/paul/commits/main", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "abc")
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/contents/.github", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{
+		    "type": "file",
+		    "name": "PAUL.yaml",
+		    "download_url": "`+serverURL+baseURLPath+`/download/PAUL.yaml"
+		}]`)
+	})
+	mux.HandleFunc("/download/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		fmt.Fprint(w, "extends: .github\n")
+	})
+	mux.HandleFunc("/repos/Spazzy757/.github/commits/HEAD", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"`+orgSHA+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, orgSHA)
+	})
+	mux.HandleFunc("/repos/Spazzy757/.github/contents/.github", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{
+		    "type": "file",
+		    "name": "PAUL.yaml",
+		    "download_url": "`+serverURL+baseURLPath+`/download/org/PAUL.yaml"
+		}]`)
+	})
+	mux.HandleFunc("/download/org/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "pull_requests:\n  stale_time: %d\n", orgStaleTime)
+	})
+
+	now := time.Now()
+	EnableCache(time.Minute)
+	defer EnableCache(0)
+	cache.now = func() time.Time { return now }
+	getStaleTime := func() int {
+		cfg, err := GetPaulConfig(context.Background(), "Spazzy757", "paul", "main", mClient)
+		assertions.NoError(err)
+		return cfg.PullRequests.StaleTime
+	}
+
+	t.Run("Test Unchanged Extended SHA Reuses Config After TTL", func(t *testing.T) {
+		assertions.Equal(5, getStaleTime())
+		now = now.Add(2 * time.Minute)
+		assertions.Equal(5, getStaleTime())
+		assertions.Equal(1, downloads)
+	})
+	t.Run("Test Changed Extended SHA Downloads Config After TTL", func(t *testing.T) {
+		orgSHA = "def"
+		orgStaleTime = 10
+		now = now.Add(2 * time.Minute)
+		assertions.Equal(10, getStaleTime())
+		assertions.Equal(2, downloads)
+	})
+}
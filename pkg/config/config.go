@@ -5,58 +5,255 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 
 	"github.com/Spazzy757/paul/pkg/types"
 	"github.com/google/go-github/v49/github"
+	"gopkg.in/yaml.v2"
 )
 
-const configFile = "PAUL.yaml"
+const (
+	configFile = "PAUL.yaml"
+	// OrgConfigRepo is the repository org wide defaults are loaded from
+	OrgConfigRepo = ".github"
+)
+
+// IsConfigFile checks if a path in a repository is a location paul reads config from
+func IsConfigFile(path string) bool {
+	return path == configFile || path == filepath.Join(".github", configFile)
+}
 
 // GetPaulConfig returns configuration for paul
+// If the repository has no config the defaults from the owners .github
+// repository are used, a repository config can build on top of another
+// config by setting extends
 func GetPaulConfig(
 	ctx context.Context,
 	owner, repo, defaultBranch string,
 	client *github.Client,
 ) (types.PaulConfig, error) {
+	if cache != nil {
+		return cache.get(ctx, owner, repo, defaultBranch, client)
+	}
+	paulCfg, _, err := loadPaulConfig(ctx, owner, repo, defaultBranch, client)
+	return paulCfg, err
+}
+
+// configSource is a repository a config was read from
+type configSource struct {
+	owner, repo string
+}
+
+// loadPaulConfig downloads the config for a repository and returns it
+// along with the repositories whose config it was built from
+func loadPaulConfig(
+	ctx context.Context,
+	owner, repo, defaultBranch string,
+	client *github.Client,
+) (types.PaulConfig, []configSource, error) {
 	var paulCfg types.PaulConfig
+	sources := []configSource{{owner: owner, repo: repo}}
+	bytesConfig, err := downloadConfig(ctx, owner, repo, defaultBranch, client)
+	if err != nil {
+		return paulCfg, sources, err
+	}
+	bytesConfig, extended, err := resolveExtends(ctx, owner, repo, bytesConfig, client)
+	if extended != nil {
+		sources = append(sources, *extended)
+	}
+	if err != nil || bytesConfig == nil {
+		return paulCfg, sources, err
+	}
+	err = paulCfg.LoadConfig(bytesConfig)
+	return paulCfg, sources, err
+}
+
+// resolveExtends merges the config with the config it extends
+// and returns the repository that was extended
+func resolveExtends(
+	ctx context.Context,
+	owner, repo string,
+	bytesConfig []byte,
+	client *github.Client,
+) ([]byte, *configSource, error) {
+	extends := OrgConfigRepo
+	if bytesConfig != nil {
+		var cfg types.PaulConfig
+		// Invalid configs are reported when they are loaded
+		if err := cfg.LoadConfig(bytesConfig); err != nil || cfg.Extends == "" {
+			return bytesConfig, nil, nil
+		}
+		extends = cfg.Extends
+	}
+	baseOwner, baseRepo := parseExtends(owner, extends)
+	// A config can't extend itself
+	if baseOwner == owner && baseRepo == repo {
+		return bytesConfig, nil, nil
+	}
+	extended := &configSource{owner: baseOwner, repo: baseRepo}
+	// The default branch of the extended repository is used
+	baseConfig, err := downloadConfig(ctx, baseOwner, baseRepo, "", client)
+	if err != nil {
+		return nil, extended, err
+	}
+	if baseConfig == nil {
+		return bytesConfig, extended, nil
+	}
+	if bytesConfig == nil {
+		return baseConfig, extended, nil
+	}
+	merged, err := MergeConfig(baseConfig, bytesConfig)
+	return merged, extended, err
+}
+
+// parseExtends turns the value of extends into an owner and repository,
+// values without an owner are resolved against the current owner
+// i.e ".github" or "Spazzy757/.github"
+func parseExtends(owner, extends string) (string, string) {
+	if parts := strings.SplitN(extends, "/", 2); len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return owner, extends
+}
+
+// HasConfig checks if the repository has a PAUL.yaml at the ref given
+func HasConfig(
+	ctx context.Context,
+	owner, repo, ref string,
+	client *github.Client,
+) (bool, error) {
+	bytesConfig, err := downloadConfig(ctx, owner, repo, ref, client)
+	return bytesConfig != nil, err
+}
+
+// downloadConfig returns the raw config from a repository
+// or nil if the repository does not have a config
+func downloadConfig(
+	ctx context.Context,
+	owner, repo, ref string,
+	client *github.Client,
+) ([]byte, error) {
 	reader, resp, err := client.Repositories.DownloadContents(
 		ctx,
 		owner,
 		repo,
 		filepath.Join(".github", configFile),
 		&github.RepositoryContentGetOptions{
-			Ref: defaultBranch,
+			Ref: ref,
 		},
 	)
 	// If 404 check in the root directory
-	if resp.StatusCode == 404 {
+	if resp != nil && resp.StatusCode == 404 {
 		reader, resp, err = client.Repositories.DownloadContents(
 			ctx,
 			owner,
 			repo,
 			configFile,
 			&github.RepositoryContentGetOptions{
-				Ref: defaultBranch,
+				Ref: ref,
 			},
 		)
 		// If still not found then return empty config
 		// but not error
-		if resp.StatusCode == 404 {
-			return paulCfg, nil
+		if resp != nil && resp.StatusCode == 404 {
+			return nil, nil
 		}
 	}
 	// Any error from downloading return empty config
 	// This means the file cant be found or paul does not have access
 	if err != nil {
-		return paulCfg, nil
+		return nil, nil
 
 	}
 	defer reader.Close()
 
 	bytesConfig, err := io.ReadAll(reader)
 	if err != nil {
-		return paulCfg, fmt.Errorf("unable to read github's response: %s", err)
+		return nil, fmt.Errorf("unable to read github's response: %s", err)
 	}
-	err = paulCfg.LoadConfig(bytesConfig)
-	return paulCfg, err
+	return bytesConfig, nil
+}
+
+/*
+MergeConfig deep merges the override config on top of the base config:
+  - nested sections such as pull_requests are merged key by key
+  - lists such as maintainers are combined, with duplicates removed
+  - any other value set in override replaces the value in base,
+    this includes explicitly setting a value to false or 0
+*/
+func MergeConfig(base, override []byte) ([]byte, error) {
+	baseMap := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(base, &baseMap); err != nil {
+		return nil, err
+	}
+	overrideMap := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(override, &overrideMap); err != nil {
+		return nil, err
+	}
+	expandLabelsShorthand(baseMap, overrideMap)
+	merged := mergeValues(baseMap, overrideMap).(map[interface{}]interface{})
+	// extends only applies to the config it is written in
+	delete(merged, "extends")
+	return yaml.Marshal(merged)
+}
+
+// expandLabelsShorthand turns "labels: true" into "labels: {enabled: true}"
+// when the other config has a labels block, so the block is merged with
+// rather than replaced by or replacing the shorthand
+func expandLabelsShorthand(configs ...map[interface{}]interface{}) {
+	hasBlock := false
+	for _, cfg := range configs {
+		if _, ok := cfg["labels"].(map[interface{}]interface{}); ok {
+			hasBlock = true
+		}
+	}
+	if !hasBlock {
+		return
+	}
+	for _, cfg := range configs {
+		if enabled, ok := cfg["labels"].(bool); ok {
+			cfg["labels"] = map[interface{}]interface{}{"enabled": enabled}
+		}
+	}
+}
+
+func mergeValues(base, override interface{}) interface{} {
+	switch o := override.(type) {
+	case map[interface{}]interface{}:
+		b, ok := base.(map[interface{}]interface{})
+		if !ok {
+			return o
+		}
+		merged := map[interface{}]interface{}{}
+		for key, value := range b {
+			merged[key] = value
+		}
+		for key, value := range o {
+			merged[key] = mergeValues(b[key], value)
+		}
+		return merged
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok {
+			return o
+		}
+		merged := append([]interface{}{}, b...)
+		for _, value := range o {
+			if !containsValue(merged, value) {
+				merged = append(merged, value)
+			}
+		}
+		return merged
+	default:
+		return o
+	}
+}
+
+func containsValue(list []interface{}, query interface{}) bool {
+	for _, value := range list {
+		if fmt.Sprint(value) == fmt.Sprint(query) {
+			return true
+		}
+	}
+	return false
 }
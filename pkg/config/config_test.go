@@ -148,3 +148,141 @@ func TestGetPaulConfig(t *testing.T) {
 		assertions.Equal(cfg.PullRequests.DogsEnabled, false)
 	})
 }
+
+func TestGetPaulConfigExtends(t *testing.T) {
+	orgConfig := `
+maintainers:
+  - Spazzy757
+labels: true
+pull_requests:
+  cats_enabled: true
+  dogs_enabled: true
+`
+	handleConfig := func(mux *http.ServeMux, serverURL, repo, config string) {
+		mux.HandleFunc(
+			"/repos/Spazzy757/"+repo+"/contents/.github",
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{
+		            "type": "file",
+		            "name": "PAUL.yaml",
+		            "download_url": "`+serverURL+baseURLPath+`/download/`+repo+`/PAUL.yaml"
+		        }]`)
+			},
+		)
+		mux.HandleFunc("/download/"+repo+"/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, config)
+		})
+	}
+	t.Run("Test Repo Without Config Uses Org Defaults", func(t *testing.T) {
+		assertions := require.New(t)
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleConfig(mux, serverURL, ".github", orgConfig)
+
+		cfg, err := GetPaulConfig(context.Background(), "Spazzy757", "paul", "main", mClient)
+		assertions.NoError(err)
+		assertions.Equal([]string{"Spazzy757"}, cfg.Maintainers)
+		assertions.True(cfg.PullRequests.CatsEnabled)
+	})
+	t.Run("Test Repo Config Extends Org Config", func(t *testing.T) {
+		assertions := require.New(t)
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleConfig(mux, serverURL, ".github", orgConfig)
+		handleConfig(mux, serverURL, "paul", `
+extends: .github
+maintainers:
+  - other
+pull_requests:
+  dogs_enabled: false
+  stale_time: 10
+`)
+
+		cfg, err := GetPaulConfig(context.Background(), "Spazzy757", "paul", "main", mClient)
+		assertions.NoError(err)
+		assertions.Equal([]string{"Spazzy757", "other"}, cfg.Maintainers)
+		assertions.True(cfg.Labels.Enabled)
+		assertions.True(cfg.PullRequests.CatsEnabled)
+		assertions.False(cfg.PullRequests.DogsEnabled)
+		assertions.Equal(10, cfg.PullRequests.StaleTime)
+		assertions.Equal("", cfg.Extends)
+	})
+	t.Run("Test Repo Config Without Extends Ignores Org Config", func(t *testing.T) {
+		assertions := require.New(t)
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleConfig(mux, serverURL, ".github", orgConfig)
+		handleConfig(mux, serverURL, "paul", `
+maintainers:
+  - other
+`)
+
+		cfg, err := GetPaulConfig(context.Background(), "Spazzy757", "paul", "main", mClient)
+		assertions.NoError(err)
+		assertions.Equal([]string{"other"}, cfg.Maintainers)
+		assertions.False(cfg.PullRequests.CatsEnabled)
+	})
+}
+
+func TestMergeConfig(t *testing.T) {
+	var mergeTests = []struct {
+		name     string
+		base     string
+		override string
+		expected string
+	}{
+		{
+			name:     "Scalars are overridden",
+			base:     "pull_requests:\n  stale_time: 5\n",
+			override: "pull_requests:\n  stale_time: 10\n",
+			expected: "pull_requests:\n  stale_time: 10\n",
+		},
+		{
+			name:     "Nested sections are merged",
+			base:     "pull_requests:\n  cats_enabled: true\n",
+			override: "pull_requests:\n  dogs_enabled: true\n",
+			expected: "pull_requests:\n  cats_enabled: true\n  dogs_enabled: true\n",
+		},
+		{
+			name:     "False overrides true",
+			base:     "branch_destroyer:\n  enabled: true\n",
+			override: "branch_destroyer:\n  enabled: false\n",
+			expected: "branch_destroyer:\n  enabled: false\n",
+		},
+		{
+			name:     "Lists are combined without duplicates",
+			base:     "maintainers:\n- a\n- b\n",
+			override: "maintainers:\n- b\n- c\n",
+			expected: "maintainers:\n- a\n- b\n- c\n",
+		},
+		{
+			name:     "Labels shorthand keeps the labels block",
+			base:     "labels:\n  prefixes:\n  - kind\n",
+			override: "labels: true\n",
+			expected: "labels:\n  enabled: true\n  prefixes:\n  - kind\n",
+		},
+		{
+			name:     "Labels block keeps the labels shorthand",
+			base:     "labels: true\n",
+			override: "labels:\n  prefixes:\n  - kind\n",
+			expected: "labels:\n  enabled: true\n  prefixes:\n  - kind\n",
+		},
+		{
+			name:     "Extends is dropped",
+			base:     "labels: true\n",
+			override: "extends: .github\n",
+			expected: "labels: true\n",
+		},
+	}
+	for _, test := range mergeTests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, err := MergeConfig([]byte(test.base), []byte(test.override))
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(merged))
+		})
+	}
+	t.Run("Invalid Config Fails", func(t *testing.T) {
+		_, err := MergeConfig([]byte(`% ^ & HHH`), []byte(""))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+)
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// schemaOverrides adds constraints to the generated schema
+// that can't be worked out from the types alone
+var schemaOverrides = map[string]map[string]interface{}{
+	"pull_requests.stale_time":                     {"minimum": 0},
+	"pull_requests.limit_pull_requests.max_number": {"minimum": 0},
+	"pull_requests.approvals.required":             {"minimum": 0},
+	// labels can also be set to true or false
+	"labels":                   {"type": []string{"boolean", "object"}},
+	"labels.definitions.color": {"pattern": "^#?[0-9a-fA-F]{6}$"},
+	"empty_description_check.message": {
+		"default": types.DefaultEmptyDescriptionMessage,
+	},
+}
+
+// Schema returns a JSON Schema for PAUL.yaml generated from types.PaulConfig
+func Schema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(types.PaulConfig{}), "")
+	schema["$schema"] = schemaDraft
+	schema["title"] = "Paul configuration"
+	return schema
+}
+
+func typeSchema(t reflect.Type, path string) map[string]interface{} {
+	var schema map[string]interface{}
+	switch t.Kind() {
+	case reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema = map[string]interface{}{"type": "integer"}
+	case reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		schema = map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), path),
+		}
+	case reflect.Map:
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), path),
+		}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			properties[name] = typeSchema(field.Type, fieldPath)
+		}
+		schema = map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	default:
+		schema = map[string]interface{}{}
+	}
+	for key, value := range schemaOverrides[path] {
+		schema[key] = value
+	}
+	return schema
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	properties := schema["properties"].(map[string]interface{})
+	t.Run("Test Schema Has Top Level Keys", func(t *testing.T) {
+		assert.Equal(t, schemaDraft, schema["$schema"])
+		assert.Equal(t, false, schema["additionalProperties"])
+		for _, key := range []string{"maintainers", "pull_requests", "labels", "branch_destroyer"} {
+			assert.Contains(t, properties, key)
+		}
+	})
+	t.Run("Test Schema Types", func(t *testing.T) {
+		maintainers := properties["maintainers"].(map[string]interface{})
+		assert.Equal(t, "array", maintainers["type"])
+		assert.Equal(t, map[string]interface{}{"type": "string"}, maintainers["items"])
+		pullRequests := properties["pull_requests"].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"type": "boolean"}, pullRequests["cats_enabled"])
+	})
+	t.Run("Test Schema Overrides", func(t *testing.T) {
+		pullRequests := properties["pull_requests"].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.Equal(t, 0, pullRequests["stale_time"].(map[string]interface{})["minimum"])
+		labels := properties["labels"].(map[string]interface{})
+		assert.Equal(t, []string{"boolean", "object"}, labels["type"])
+		definitions := labels["properties"].(map[string]interface{})["definitions"].(map[string]interface{})
+		color := definitions["items"].(map[string]interface{})["properties"].(map[string]interface{})["color"]
+		assert.Equal(t, "^#?[0-9a-fA-F]{6}$", color.(map[string]interface{})["pattern"])
+	})
+}
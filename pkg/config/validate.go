@@ -0,0 +1,355 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const (
+	// SeverityError is used for problems that stop paul from reading the config
+	SeverityError = "error"
+	// SeverityWarning is used for settings that are valid but likely a mistake
+	SeverityWarning = "warning"
+)
+
+var (
+	yamlLineError = regexp.MustCompile(`line (\d+): (.*)`)
+	labelColor    = regexp.MustCompile(`^#?[0-9a-fA-F]{6}$`)
+)
+
+// Problem is an error or warning found in a config
+type Problem struct {
+	Line     int
+	Severity string
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", p.Line, p.Severity, p.Message)
+}
+
+// HasErrors checks if any of the problems are errors
+func HasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate strictly checks a config, reporting unknown keys,
+// values of the wrong type and invalid values
+func Validate(config []byte) []Problem {
+	var problems []Problem
+	var cfg types.PaulConfig
+	if err := yaml.UnmarshalStrict(config, &cfg); err != nil {
+		problems = append(problems, yamlProblems(err)...)
+		// Syntax errors stop the config from being read at all
+		if _, ok := err.(*yaml.TypeError); !ok {
+			return problems
+		}
+	}
+	root := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(config, root); err != nil {
+		return problems
+	}
+	lines := &lineFinder{root: root}
+	for _, check := range checks {
+		for _, problem := range check(cfg, lines) {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// yamlProblems splits a yaml error into problems for each line
+func yamlProblems(err error) []Problem {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+	var problems []Problem
+	for _, message := range messages {
+		problem := Problem{Severity: SeverityError, Message: message}
+		if match := yamlLineError.FindStringSubmatch(message); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = match[2]
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// lineFinder looks up the line a key is set on
+type lineFinder struct {
+	root *yamlv3.Node
+}
+
+// line returns the line of the key at the path i.e "pull_requests.stale_time"
+// or 0 if the key is not set
+func (l *lineFinder) line(path string) int {
+	node := l.root
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := 0
+	for _, key := range strings.Split(path, ".") {
+		if node.Kind != yamlv3.MappingNode {
+			return 0
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				line = node.Content[i].Line
+				node = node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	return line
+}
+
+func (l *lineFinder) errorf(path, format string, args ...interface{}) Problem {
+	return Problem{
+		Line:     l.line(path),
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func (l *lineFinder) warnf(path, format string, args ...interface{}) Problem {
+	return Problem{
+		Line:     l.line(path),
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// checks validate the values of a config that has been read
+var checks = []func(types.PaulConfig, *lineFinder) []Problem{
+	checkPullRequests,
+	checkMaintainers,
+	checkEmptyDescriptionCheck,
+	checkBranchDestroyer,
+	checkLabels,
+	checkCustomCommands,
+}
+
+func checkPullRequests(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	var problems []Problem
+	pr := cfg.PullRequests
+	if pr.StaleTime < 0 {
+		problems = append(problems, lines.errorf(
+			"pull_requests.stale_time",
+			"stale_time must not be negative, got %d", pr.StaleTime,
+		))
+	}
+	if pr.LimitPullRequests.MaxNumber < 0 {
+		problems = append(problems, lines.errorf(
+			"pull_requests.limit_pull_requests.max_number",
+			"max_number must not be negative, got %d", pr.LimitPullRequests.MaxNumber,
+		))
+	}
+	if len(pr.Retest.Checks) > 0 && !pr.Retest.Enabled {
+		problems = append(problems, lines.warnf(
+			"pull_requests.retest.checks",
+			"checks has no effect unless retest is enabled",
+		))
+	}
+	if pr.Approvals.Required < 0 {
+		problems = append(problems, lines.errorf(
+			"pull_requests.approvals.required",
+			"required must not be negative, got %d", pr.Approvals.Required,
+		))
+	}
+	if pr.Approvals.Required > len(cfg.Maintainers) {
+		problems = append(problems, lines.warnf(
+			"pull_requests.approvals.required",
+			"%d approvals are required but there are only %d maintainers",
+			pr.Approvals.Required, len(cfg.Maintainers),
+		))
+	}
+	if pr.Approvals.DismissOnPush && pr.Approvals.Required <= 0 {
+		problems = append(problems, lines.warnf(
+			"pull_requests.approvals.dismiss_on_push",
+			"dismiss_on_push has no effect on merging when no approvals are required",
+		))
+	}
+	return problems
+}
+
+func checkMaintainers(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	if len(cfg.Maintainers) > 0 {
+		return nil
+	}
+	maintainerOnly := map[string]bool{
+		"labels":                       cfg.Labels.Enabled,
+		"pull_requests.allow_approval": cfg.PullRequests.AllowApproval,
+		"pull_requests.assign":         cfg.PullRequests.Assign,
+	}
+	var problems []Problem
+	for path, enabled := range maintainerOnly {
+		if enabled {
+			problems = append(problems, lines.warnf(
+				path,
+				"%s is enabled but can only be used by maintainers and none are set",
+				path,
+			))
+		}
+	}
+	return problems
+}
+
+func checkEmptyDescriptionCheck(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	check := cfg.EmptyDescriptionCheck
+	var problems []Problem
+	if check.Enforced && !check.Enabled {
+		problems = append(problems, lines.warnf(
+			"empty_description_check.enforced",
+			"enforced has no effect unless empty_description_check is enabled",
+		))
+	}
+	if check.Template != "" && !check.Enabled {
+		problems = append(problems, lines.warnf(
+			"empty_description_check.template",
+			"template has no effect unless empty_description_check is enabled",
+		))
+	}
+	if check.Template != "" && check.Enforced {
+		problems = append(problems, lines.warnf(
+			"empty_description_check.enforced",
+			"Pull Requests are not closed when a template is set",
+		))
+	}
+	return problems
+}
+
+func checkBranchDestroyer(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	destroyer := cfg.BranchDestroyer
+	if len(destroyer.ProtectedBranches) > 0 && !destroyer.Enabled {
+		return []Problem{lines.warnf(
+			"branch_destroyer.protected_branches",
+			"protected_branches has no effect unless branch_destroyer is enabled",
+		)}
+	}
+	return nil
+}
+
+func checkLabels(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	var problems []Problem
+	seen := map[string]string{}
+	for i, definition := range cfg.Labels.Definitions {
+		if definition.Name == "" {
+			problems = append(problems, lines.errorf(
+				"labels.definitions",
+				"label definition %d has no name", i+1,
+			))
+			continue
+		}
+		if definition.Color != "" && !labelColor.MatchString(definition.Color) {
+			problems = append(problems, lines.errorf(
+				"labels.definitions",
+				"label %q has invalid color %q, must be a hex color i.e d73a4a",
+				definition.Name,
+				definition.Color,
+			))
+		}
+		for _, name := range append([]string{definition.Name}, definition.Aliases...) {
+			key := strings.ToLower(name)
+			if other, ok := seen[key]; ok {
+				problems = append(problems, lines.errorf(
+					"labels.definitions",
+					"%q is used by both label %q and label %q", name, other, definition.Name,
+				))
+				continue
+			}
+			seen[key] = definition.Name
+		}
+	}
+	for _, prefix := range cfg.Labels.Prefixes {
+		if prefix == "" || strings.ContainsAny(prefix, "/ \t") {
+			problems = append(problems, lines.errorf(
+				"labels.prefixes",
+				"label prefix %q must be a single word without a /", prefix,
+			))
+		}
+	}
+	for level, label := range cfg.Labels.Priorities {
+		if label == "" {
+			problems = append(problems, lines.errorf(
+				"labels.priorities",
+				"priority %q has no label", level,
+			))
+			continue
+		}
+		if len(cfg.Labels.Definitions) > 0 {
+			if _, ok := cfg.Labels.Lookup(label); !ok {
+				problems = append(problems, lines.warnf(
+					"labels.priorities",
+					"priority %q uses label %q which is not defined", level, label,
+				))
+			}
+		}
+	}
+	return problems
+}
+
+func checkCustomCommands(cfg types.PaulConfig, lines *lineFinder) []Problem {
+	var problems []Problem
+	for name, command := range cfg.CustomCommands {
+		path := "custom_commands." + name
+		switch {
+		case strings.HasPrefix(name, "/") || strings.ContainsAny(name, " \t"):
+			problems = append(problems, lines.errorf(
+				path,
+				"custom command %q must be a single word without the /", name,
+			))
+		case cfg.IsCommand(name):
+			problems = append(problems, lines.errorf(
+				path,
+				"custom command %q can't replace a built-in command", name,
+			))
+		case command.Response != "" && command.Alias != "":
+			problems = append(problems, lines.errorf(
+				path,
+				"custom command %q can have a response or an alias, not both", name,
+			))
+		case command.Response == "" && command.Alias == "":
+			problems = append(problems, lines.errorf(
+				path,
+				"custom command %q needs a response or an alias", name,
+			))
+		// Paul's response would be read as a command
+		case strings.HasPrefix(command.Response, "/"):
+			problems = append(problems, lines.errorf(
+				path+".response",
+				"custom command %q has a response starting with / which would run a command", name,
+			))
+		case command.Alias != "":
+			alias := strings.TrimPrefix(command.Alias, "/")
+			if !cfg.IsCommand(alias) {
+				problems = append(problems, lines.errorf(
+					path+".alias",
+					"custom command %q is an alias for %q which is not a built-in command",
+					name,
+					command.Alias,
+				))
+			}
+		}
+	}
+	return problems
+}
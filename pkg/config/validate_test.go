@@ -0,0 +1,157 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Test Repository Config Is Valid", func(t *testing.T) {
+		yamlFile, err := os.ReadFile("../../.github/PAUL.yaml")
+		require.NoError(t, err)
+		assert.Empty(t, Validate(yamlFile))
+	})
+	var validateTests = []struct {
+		name     string
+		config   string
+		expected []Problem
+	}{
+		{
+			name:   "Unknown Keys",
+			config: "maintainers:\n  - Spazzy757\npull_requests:\n  stale_tim: 5\ndco_chek: true\n",
+			expected: []Problem{
+				{Line: 4, Severity: SeverityError, Message: "field stale_tim not found in type types.PullRequests"},
+				{Line: 5, Severity: SeverityError, Message: "field dco_chek not found in type types.PaulConfig"},
+			},
+		},
+		{
+			name:   "Type Errors",
+			config: "pull_requests:\n  stale_time: soon\n",
+			expected: []Problem{
+				{Line: 2, Severity: SeverityError, Message: "cannot unmarshal !!str `soon` into int"},
+			},
+		},
+		{
+			name:   "Invalid Values",
+			config: "pull_requests:\n  stale_time: -1\n  limit_pull_requests:\n    max_number: -2\n",
+			expected: []Problem{
+				{Line: 2, Severity: SeverityError, Message: "stale_time must not be negative, got -1"},
+				{Line: 4, Severity: SeverityError, Message: "max_number must not be negative, got -2"},
+			},
+		},
+		{
+			name:   "Warnings",
+			config: "labels: true\nempty_description_check:\n  enforced: true\nbranch_destroyer:\n  protected_branches:\n    - main\n",
+			expected: []Problem{
+				{Line: 1, Severity: SeverityWarning, Message: "labels is enabled but can only be used by maintainers and none are set"},
+				{Line: 3, Severity: SeverityWarning, Message: "enforced has no effect unless empty_description_check is enabled"},
+				{Line: 5, Severity: SeverityWarning, Message: "protected_branches has no effect unless branch_destroyer is enabled"},
+			},
+		},
+		{
+			name:   "Description Template",
+			config: "empty_description_check:\n  enforced: true\n  template: |\n    ## What\n",
+			expected: []Problem{
+				{Line: 2, Severity: SeverityWarning, Message: "enforced has no effect unless empty_description_check is enabled"},
+				{Line: 3, Severity: SeverityWarning, Message: "template has no effect unless empty_description_check is enabled"},
+				{Line: 2, Severity: SeverityWarning, Message: "Pull Requests are not closed when a template is set"},
+			},
+		},
+		{
+			name:   "Label Definitions",
+			config: "labels:\n  enabled: true\n  definitions:\n    - name: bug\n      color: red\n      aliases: [defect]\n    - name: Defect\n    - color: ffffff\n",
+			expected: []Problem{
+				{Line: 1, Severity: SeverityWarning, Message: "labels is enabled but can only be used by maintainers and none are set"},
+				{Line: 3, Severity: SeverityError, Message: `label "bug" has invalid color "red", must be a hex color i.e d73a4a`},
+				{Line: 3, Severity: SeverityError, Message: `"Defect" is used by both label "bug" and label "Defect"`},
+				{Line: 3, Severity: SeverityError, Message: "label definition 3 has no name"},
+			},
+		},
+		{
+			name:   "Label Prefixes",
+			config: "labels:\n  prefixes:\n    - kind\n    - area/\n",
+			expected: []Problem{
+				{Line: 2, Severity: SeverityError, Message: `label prefix "area/" must be a single word without a /`},
+			},
+		},
+		{
+			name:   "Label Priorities",
+			config: "labels:\n  definitions:\n    - name: priority/high\n  priorities:\n    high: priority/high\n    low: priority/low\n    none: \"\"\n",
+			expected: []Problem{
+				{Line: 4, Severity: SeverityWarning, Message: `priority "low" uses label "priority/low" which is not defined`},
+				{Line: 4, Severity: SeverityError, Message: `priority "none" has no label`},
+			},
+		},
+		{
+			name:   "Unknown Label Keys",
+			config: "labels:\n  definitions:\n    - name: bug\n      colour: d73a4a\n",
+			expected: []Problem{
+				{Line: 4, Severity: SeverityError, Message: "field colour not found in type types.LabelDefinition"},
+			},
+		},
+		{
+			name:   "Approvals",
+			config: "maintainers:\n  - Spazzy757\npull_requests:\n  approvals:\n    required: 2\n    dismiss_on_push: true\n",
+			expected: []Problem{
+				{Line: 5, Severity: SeverityWarning, Message: "2 approvals are required but there are only 1 maintainers"},
+			},
+		},
+		{
+			name:   "Retest Checks Without Retest",
+			config: "pull_requests:\n  retest:\n    checks:\n      - build\n",
+			expected: []Problem{
+				{Line: 3, Severity: SeverityWarning, Message: "checks has no effect unless retest is enabled"},
+			},
+		},
+		{
+			name:   "Invalid Approvals",
+			config: "pull_requests:\n  approvals:\n    required: -1\n    dismiss_on_push: true\n",
+			expected: []Problem{
+				{Line: 3, Severity: SeverityError, Message: "required must not be negative, got -1"},
+				{Line: 4, Severity: SeverityWarning, Message: "dismiss_on_push has no effect on merging when no approvals are required"},
+			},
+		},
+		{
+			name: "Custom Commands",
+			config: "custom_commands:\n  docs:\n    response: See the docs {{sender}}\n  ship:\n    alias: /merge\n" +
+				"  merge:\n    response: no\n  both:\n    response: a\n    alias: merge\n  none: {}\n  deploy:\n    alias: deploy-it\n" +
+				"  loop:\n    response: /merge\n",
+			expected: []Problem{
+				{Line: 6, Severity: SeverityError, Message: `custom command "merge" can't replace a built-in command`},
+				{Line: 8, Severity: SeverityError, Message: `custom command "both" can have a response or an alias, not both`},
+				{Line: 11, Severity: SeverityError, Message: `custom command "none" needs a response or an alias`},
+				{Line: 13, Severity: SeverityError, Message: `custom command "deploy" is an alias for "deploy-it" which is not a built-in command`},
+				{Line: 15, Severity: SeverityError, Message: `custom command "loop" has a response starting with / which would run a command`},
+			},
+		},
+		{
+			name: "Custom Commands For Label Prefixes",
+			config: "labels:\n  prefixes:\n  - kind\ncustom_commands:\n  unkind:\n    alias: /remove-kind\n" +
+				"  remove-kind:\n    response: no\n",
+			expected: []Problem{
+				{Line: 7, Severity: SeverityError, Message: `custom command "remove-kind" can't replace a built-in command`},
+			},
+		},
+		{
+			name:   "Syntax Error",
+			config: "maintainers:\n  - a\n b: c\n",
+			expected: []Problem{
+				{Line: 2, Severity: SeverityError, Message: "did not find expected key"},
+			},
+		},
+	}
+	for _, test := range validateTests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ElementsMatch(t, test.expected, Validate([]byte(test.config)))
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, HasErrors(nil))
+	assert.False(t, HasErrors([]Problem{{Severity: SeverityWarning}}))
+	assert.True(t, HasErrors([]Problem{{Severity: SeverityWarning}, {Severity: SeverityError}}))
+}
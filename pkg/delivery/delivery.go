@@ -0,0 +1,85 @@
+package delivery
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	id        string
+	expiresAt time.Time
+}
+
+// Store remembers webhook delivery IDs so redelivered webhooks can be skipped,
+// IDs are forgotten after the TTL or once more than Size IDs are held
+type Store struct {
+	TTL  time.Duration
+	Size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+// NewStore returns an empty Store
+func NewStore(size int, ttl time.Duration) *Store {
+	return &Store{
+		TTL:     ttl,
+		Size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+		now:     time.Now,
+	}
+}
+
+// Reserve records the delivery ID, it returns false if the ID has already
+// been seen and has not expired
+func (s *Store) Reserve(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.evict(now)
+	if _, ok := s.entries[id]; ok {
+		return false
+	}
+	s.entries[id] = s.order.PushBack(&entry{id: id, expiresAt: now.Add(s.TTL)})
+	for s.Size > 0 && s.order.Len() > s.Size {
+		s.remove(s.order.Front())
+	}
+	return true
+}
+
+// Release forgets the delivery ID so a redelivery is processed again,
+// it is used when handling the delivery failed
+func (s *Store) Release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[id]; ok {
+		s.remove(element)
+	}
+}
+
+// Len returns the number of delivery IDs held
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(s.now())
+	return s.order.Len()
+}
+
+// evict removes expired IDs, the oldest entries are at the front
+func (s *Store) evict(now time.Time) {
+	for element := s.order.Front(); element != nil; element = s.order.Front() {
+		if now.Before(element.Value.(*entry).expiresAt) {
+			return
+		}
+		s.remove(element)
+	}
+}
+
+func (s *Store) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*entry).id)
+}
@@ -0,0 +1,44 @@
+package delivery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("Test Duplicate Delivery Is Rejected", func(t *testing.T) {
+		s := NewStore(10, time.Minute)
+		assert.Equal(t, true, s.Reserve("a"))
+		assert.Equal(t, false, s.Reserve("a"))
+		assert.Equal(t, true, s.Reserve("b"))
+	})
+	t.Run("Test Released Delivery Can Be Reserved Again", func(t *testing.T) {
+		s := NewStore(10, time.Minute)
+		assert.Equal(t, true, s.Reserve("a"))
+		s.Release("a")
+		assert.Equal(t, true, s.Reserve("a"))
+	})
+	t.Run("Test Deliveries Expire After TTL", func(t *testing.T) {
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		s := NewStore(10, time.Minute)
+		s.now = func() time.Time { return now }
+		assert.Equal(t, true, s.Reserve("a"))
+		now = now.Add(30 * time.Second)
+		assert.Equal(t, true, s.Reserve("b"))
+		now = now.Add(45 * time.Second)
+		assert.Equal(t, 1, s.Len())
+		assert.Equal(t, true, s.Reserve("a"))
+		assert.Equal(t, false, s.Reserve("b"))
+	})
+	t.Run("Test Oldest Delivery Is Evicted When Full", func(t *testing.T) {
+		s := NewStore(2, time.Minute)
+		assert.Equal(t, true, s.Reserve("a"))
+		assert.Equal(t, true, s.Reserve("b"))
+		assert.Equal(t, true, s.Reserve("c"))
+		assert.Equal(t, 2, s.Len())
+		assert.Equal(t, false, s.Reserve("c"))
+		assert.Equal(t, true, s.Reserve("a"))
+	})
+}
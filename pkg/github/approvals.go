@@ -0,0 +1,277 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	paulclient "github.com/Spazzy757/paul/pkg/client"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	lgtmLabel                 = "lgtm"
+	notEnoughApprovalsMessage = "This Pull Request needs %d approval(s) from maintainers before it can be merged, it has %d"
+	approvalRecordedMessage   = "Approval from @%v recorded for commit %v"
+)
+
+// lgtmHandler handles the /lgtm command, "/lgtm cancel" takes it back
+func lgtmHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error {
+	if !event.Issue.IsPullRequest() ||
+		!checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) ||
+		event.Sender.GetLogin() == event.Issue.User.GetLogin() {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	number := event.Issue.GetNumber()
+	if len(args) > 0 && args[0] == "cancel" {
+		pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+		if err != nil {
+			return err
+		}
+		approvers, err := getApprovers(ctx, client, cfg, pr)
+		if err != nil || len(approvers) > 0 {
+			return err
+		}
+		res, err := client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, lgtmLabel)
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	err := syncLabels(ctx, client, owner, repo, nil, []*github.Label{labelByName(lgtmLabel)})
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Issues.AddLabelsToIssue(ctx, owner, repo, number, []string{lgtmLabel})
+	if err != nil {
+		return err
+	}
+	return recordApproval(ctx, cfg, event, client)
+}
+
+// recordApproval comments the commit a /lgtm or /approve was given for, when
+// approvals are dismissed on push only approvals recorded for the head commit
+// count as comments aren't tied to a commit
+func recordApproval(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+) error {
+	if !cfg.PullRequests.Approvals.DismissOnPush {
+		return nil
+	}
+	pr, _, err := client.PullRequests.Get(
+		ctx,
+		event.Repo.Owner.GetLogin(),
+		event.Repo.GetName(),
+		event.Issue.GetNumber(),
+	)
+	if err != nil {
+		return err
+	}
+	return createIssueComment(
+		ctx,
+		event,
+		client,
+		fmt.Sprintf(approvalRecordedMessage, event.Sender.GetLogin(), pr.Head.GetSHA()),
+	)
+}
+
+// appComment is an issue comment with the Github App that made it,
+// go-github doesn't decode performed_via_github_app
+type appComment struct {
+	github.IssueComment
+	PerformedViaGithubApp *github.App `json:"performed_via_github_app,omitempty"`
+}
+
+// listAppComments returns a page of a Pull Request's comments
+func listAppComments(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	number int,
+	opts *github.ListOptions,
+) ([]*appComment, *github.Response, error) {
+	query := url.Values{}
+	query.Set("per_page", strconv.Itoa(opts.PerPage))
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	u := fmt.Sprintf("repos/%v/%v/issues/%d/comments?%v", owner, repo, number, query.Encode())
+	req, err := client.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var comments []*appComment
+	res, err := client.Do(ctx, req, &comments)
+	return comments, res, err
+}
+
+// recordedApproval returns the user and commit of an approval recorded by Paul,
+// only comments made by Paul's own Github App are trusted as other apps and
+// bots could make the same comment
+func recordedApproval(comment *appComment) (string, string, bool) {
+	appID := paulclient.ApplicationID()
+	if appID == 0 || comment.PerformedViaGithubApp.GetID() != appID {
+		return "", "", false
+	}
+	var user, sha string
+	if _, err := fmt.Sscanf(comment.GetBody(), approvalRecordedMessage, &user, &sha); err != nil {
+		return "", "", false
+	}
+	return user, sha, true
+}
+
+// dismissApprovals removes the lgtm label when new commits are pushed
+func dismissApprovals(
+	ctx context.Context,
+	cfg types.PaulConfig,
+	client *github.Client,
+	event *github.PullRequestEvent,
+) error {
+	if !cfg.PullRequests.Approvals.DismissOnPush ||
+		event.GetAction() != "synchronize" ||
+		!hasLabel(event.PullRequest.Labels, lgtmLabel) {
+		return nil
+	}
+	pr := event.PullRequest
+	res, err := client.Issues.RemoveLabelForIssue(
+		ctx,
+		pr.Base.Repo.Owner.GetLogin(),
+		pr.Base.Repo.GetName(),
+		pr.GetNumber(),
+		lgtmLabel,
+	)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// approvalsMessage returns why a Pull Request can't be merged yet, or an
+// empty string when it has enough approvals
+func approvalsMessage(
+	ctx context.Context,
+	client *github.Client,
+	cfg *types.PaulConfig,
+	pr *github.PullRequest,
+) (string, error) {
+	required := cfg.PullRequests.Approvals.Required
+	if required <= 0 {
+		return "", nil
+	}
+	approvers, err := getApprovers(ctx, client, cfg, pr)
+	if err != nil {
+		return "", err
+	}
+	if len(approvers) >= required {
+		return "", nil
+	}
+	return fmt.Sprintf(notEnoughApprovalsMessage, required, len(approvers)), nil
+}
+
+// getApprovers returns the maintainers that approved the Pull Request with a
+// review, /approve or /lgtm. When approvals are dismissed on push only
+// approvals for the head commit count
+func getApprovers(
+	ctx context.Context,
+	client *github.Client,
+	cfg *types.PaulConfig,
+	pr *github.PullRequest,
+) (map[string]bool, error) {
+	owner := pr.Base.Repo.Owner.GetLogin()
+	repo := pr.Base.Repo.GetName()
+	dismissOnPush := cfg.PullRequests.Approvals.DismissOnPush
+	approvers := map[string]bool{}
+	reviewOpts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, res, err := client.PullRequests.ListReviews(ctx, owner, repo, pr.GetNumber(), reviewOpts)
+		if err != nil {
+			return nil, err
+		}
+		// reviews are oldest first so the latest review from a user wins
+		for _, review := range reviews {
+			user := review.User.GetLogin()
+			if !checkStringInList(cfg.Maintainers, user) {
+				continue
+			}
+			switch review.GetState() {
+			case "APPROVED":
+				approvers[user] = !dismissOnPush || review.GetCommitID() == pr.Head.GetSHA()
+			case "CHANGES_REQUESTED", "DISMISSED":
+				approvers[user] = false
+			}
+			// /lgtm can also be given in the body of a review
+			if cmd, args := getCommand(review.GetBody()); cmd == "lgtm" {
+				approvers[user] = (len(args) == 0 || args[0] != "cancel") &&
+					(!dismissOnPush || review.GetCommitID() == pr.Head.GetSHA())
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		reviewOpts.Page = res.NextPage
+	}
+	commentOpts := &github.ListOptions{PerPage: 100}
+	for {
+		comments, res, err := listAppComments(ctx, client, owner, repo, pr.GetNumber(), commentOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			if user, sha, ok := recordedApproval(comment); ok {
+				if dismissOnPush && sha == pr.Head.GetSHA() &&
+					checkStringInList(cfg.Maintainers, user) {
+					approvers[user] = true
+				}
+				continue
+			}
+			user := comment.User.GetLogin()
+			if !checkStringInList(cfg.Maintainers, user) {
+				continue
+			}
+			cmd, args := getCommand(comment.GetBody())
+			switch {
+			case cmd == "lgtm" && len(args) > 0 && args[0] == "cancel":
+				approvers[user] = false
+			// the approval is counted from the comment Paul records it in
+			case (cmd == "lgtm" || cmd == "approve") && !dismissOnPush:
+				approvers[user] = true
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		commentOpts.Page = res.NextPage
+	}
+	// authors can't approve their own Pull Requests
+	delete(approvers, pr.User.GetLogin())
+	for user, approved := range approvers {
+		if !approved {
+			delete(approvers, user)
+		}
+	}
+	return approvers, nil
+}
+
+// hasLabel checks if the labels include the label given
+func hasLabel(labels []*github.Label, name string) bool {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
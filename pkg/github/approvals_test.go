@@ -0,0 +1,238 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func getApprovalsPullRequest() *github.PullRequest {
+	return &github.PullRequest{
+		Number: github.Int(9),
+		User:   &github.User{Login: github.String("author")},
+		Head:   &github.PullRequestBranch{SHA: github.String("deadbeef")},
+		Base: &github.PullRequestBranch{
+			Repo: &github.Repository{
+				Name:  github.String("paul"),
+				Owner: &github.User{Login: github.String("Spazzy757")},
+			},
+		},
+	}
+}
+
+func handleApprovals(mux *http.ServeMux, reviews, comments string) {
+	mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, reviews)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, comments)
+	})
+}
+
+func TestGetApprovers(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"alice", "bob", "carol", "dave", "author"}}
+	t.Run("Test Reviews And Comments Are Counted", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleApprovals(
+			mux,
+			`[
+			  {"user": {"login": "alice"}, "state": "APPROVED", "commit_id": "cafe"},
+			  {"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "cafe"},
+			  {"user": {"login": "bob"}, "state": "CHANGES_REQUESTED", "commit_id": "deadbeef"},
+			  {"user": {"login": "outsider"}, "state": "APPROVED", "commit_id": "deadbeef"}
+			]`,
+			`[
+			  {"user": {"login": "carol"}, "body": "/lgtm"},
+			  {"user": {"login": "dave"}, "body": "/lgtm"},
+			  {"user": {"login": "dave"}, "body": "/lgtm cancel"},
+			  {"user": {"login": "author"}, "body": "/lgtm"},
+			  {"user": {"login": "outsider"}, "body": "/lgtm"}
+			]`,
+		)
+		approvers, err := getApprovers(context.Background(), mClient, cfg, getApprovalsPullRequest())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, map[string]bool{"alice": true, "carol": true}, approvers)
+	})
+	t.Run("Test Approvals Before The Last Push Are Dismissed", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleApprovals(
+			mux,
+			`[
+			  {"user": {"login": "alice"}, "state": "APPROVED", "commit_id": "cafe"},
+			  {"user": {"login": "bob"}, "state": "APPROVED", "commit_id": "deadbeef"}
+			]`,
+			`[
+			  {"user": {"login": "carol"}, "body": "/lgtm"},
+			  {"user": {"login": "paul[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 1}, "body": "Approval from @carol recorded for commit cafe"},
+			  {"user": {"login": "dave"}, "body": "/approve"},
+			  {"user": {"login": "paul[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 1}, "body": "Approval from @dave recorded for commit deadbeef"},
+			  {"user": {"login": "erin"}, "body": "/lgtm"},
+			  {"user": {"login": "paul[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 1}, "body": "Approval from @erin recorded for commit deadbeef"},
+			  {"user": {"login": "mallory", "type": "User"}, "body": "Approval from @alice recorded for commit deadbeef"},
+			  {"user": {"login": "github-actions[bot]", "type": "Bot"}, "performed_via_github_app": {"id": 2}, "body": "Approval from @carol recorded for commit deadbeef"}
+			]`,
+		)
+		t.Setenv("APPLICATION_ID", "1")
+		dismissCfg := *cfg
+		dismissCfg.PullRequests.Approvals.DismissOnPush = true
+		approvers, err := getApprovers(context.Background(), mClient, &dismissCfg, getApprovalsPullRequest())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, map[string]bool{"bob": true, "dave": true}, approvers)
+	})
+}
+
+func TestApprovalsMessage(t *testing.T) {
+	t.Run("Test No Approvals Required", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		message, err := approvalsMessage(context.Background(), mClient, &types.PaulConfig{}, getApprovalsPullRequest())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "", message)
+	})
+	cfg := &types.PaulConfig{
+		Maintainers:  []string{"alice", "bob"},
+		PullRequests: types.PullRequests{Approvals: types.Approvals{Required: 2}},
+	}
+	t.Run("Test Not Enough Approvals", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleApprovals(mux, `[{"user": {"login": "alice"}, "state": "APPROVED"}]`, `[]`)
+		message, err := approvalsMessage(context.Background(), mClient, cfg, getApprovalsPullRequest())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, fmt.Sprintf(notEnoughApprovalsMessage, 2, 1), message)
+	})
+	t.Run("Test Enough Approvals", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleApprovals(
+			mux,
+			`[{"user": {"login": "alice"}, "state": "APPROVED"}]`,
+			`[{"user": {"login": "bob"}, "body": "/lgtm"}]`,
+		)
+		message, err := approvalsMessage(context.Background(), mClient, cfg, getApprovalsPullRequest())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "", message)
+	})
+}
+
+func TestLgtmHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"Spazzy757", "alice"}}
+	t.Run("Test Lgtm Adds Label", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[{"name":"lgtm"}]`)
+				return
+			}
+			t.Errorf("lgtm label should not be created again")
+		})
+		labeled := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			var v []string
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, []string{lgtmLabel}, v)
+			labeled = true
+			fmt.Fprint(w, `[]`)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("alice")
+		err := lgtmHandler(context.Background(), cfg, event, mClient, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, labeled)
+	})
+	t.Run("Test Lgtm Is Recorded For The Head Commit", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[]`)
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"lgtm"}]`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[]`)
+		})
+		recorded := handleExpectedComment(t, mux, fmt.Sprintf(approvalRecordedMessage, "alice", "deadbeef"))
+		dismissCfg := *cfg
+		dismissCfg.PullRequests.Approvals.DismissOnPush = true
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("alice")
+		err := lgtmHandler(context.Background(), &dismissCfg, event, mClient, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *recorded)
+	})
+	t.Run("Test Lgtm Cancel Removes Label", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[{"name":"lgtm"}]`)
+		handleApprovals(
+			mux,
+			`[]`,
+			`[{"user": {"login": "alice"}, "body": "/lgtm"}, {"user": {"login": "alice"}, "body": "/lgtm cancel"}]`,
+		)
+		removed := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels/lgtm", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			removed = true
+			fmt.Fprint(w, `[]`)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("alice")
+		err := lgtmHandler(context.Background(), cfg, event, mClient, []string{"cancel"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, removed)
+	})
+	t.Run("Test Authors Can Not Lgtm", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := lgtmHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, nil)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestDismissApprovals(t *testing.T) {
+	cfg := types.PaulConfig{
+		PullRequests: types.PullRequests{Approvals: types.Approvals{Required: 1, DismissOnPush: true}},
+	}
+	event := &github.PullRequestEvent{
+		Action:      github.String("synchronize"),
+		PullRequest: getApprovalsPullRequest(),
+	}
+	event.PullRequest.Labels = []*github.Label{{Name: github.String(lgtmLabel)}}
+	t.Run("Test Push Removes Lgtm Label", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		removed := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels/lgtm", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			removed = true
+			fmt.Fprint(w, `[]`)
+		})
+		err := dismissApprovals(context.Background(), cfg, mClient, event)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, removed)
+	})
+	t.Run("Test Approvals Kept When Not Configured", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := dismissApprovals(context.Background(), types.PaulConfig{}, mClient, event)
+		assert.Equal(t, nil, err)
+	})
+}
@@ -4,20 +4,26 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/Spazzy757/paul/pkg/config"
 	"github.com/Spazzy757/paul/pkg/helpers"
 	"github.com/google/go-github/v49/github"
 )
 
 const (
-	dco      = "Developer Certificate Of Origin"
-	verified = "Commits Are Verified"
-	success  = "success"
-	started  = "in_progress"
-	neutral  = "neutral"
-	failed   = "action_required"
+	dco              = "Developer Certificate Of Origin"
+	verified         = "Commits Are Verified"
+	configValidation = "PAUL.yaml Validation"
+	hold             = "Hold"
+	success          = "success"
+	started          = "in_progress"
+	completed        = "completed"
+	neutral          = "neutral"
+	failed           = "action_required"
+	failure          = "failure"
 )
 
 var isAnonymousSignature = regexp.MustCompile("Signed-off-by:(.*)noreply.github.com")
@@ -105,6 +111,109 @@ func createVerifyCheck(
 	return check
 }
 
+// createConfigCheck reports the problems found in each config file
+func createConfigCheck(
+	event *github.PullRequestEvent,
+	problemsByFile map[string][]config.Problem,
+) github.CreateCheckRunOptions {
+	now := github.Timestamp{Time: time.Now()}
+	status := completed
+	conclusion := success
+	title := "Valid PAUL.yaml"
+	summary := "No problems were found in the config"
+	var annotations []*github.CheckRunAnnotation
+	var text strings.Builder
+	errorCount, warningCount := 0, 0
+	files := make([]string, 0, len(problemsByFile))
+	for file := range problemsByFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		for _, problem := range problemsByFile[file] {
+			level := "warning"
+			if problem.Severity == config.SeverityError {
+				level = "failure"
+				errorCount++
+			} else {
+				warningCount++
+			}
+			// Annotations have to point at a line
+			line := problem.Line
+			if line == 0 {
+				line = 1
+			}
+			annotations = append(annotations, &github.CheckRunAnnotation{
+				Path:            github.String(file),
+				StartLine:       github.Int(line),
+				EndLine:         github.Int(line),
+				AnnotationLevel: github.String(level),
+				Message:         github.String(problem.Message),
+			})
+			fmt.Fprintf(&text, "- `%v` %v\n", file, problem)
+		}
+	}
+	if errorCount > 0 {
+		conclusion = failure
+		title = "Invalid PAUL.yaml"
+	}
+	if errorCount > 0 || warningCount > 0 {
+		summary = fmt.Sprintf("Found %d error(s) and %d warning(s) in the config", errorCount, warningCount)
+	}
+	details := text.String()
+	return github.CreateCheckRunOptions{
+		Name:        configValidation,
+		HeadSHA:     event.PullRequest.Head.GetSHA(),
+		Status:      &status,
+		Conclusion:  &conclusion,
+		StartedAt:   &now,
+		CompletedAt: &now,
+		Output: &github.CheckRunOutput{
+			Title:       &title,
+			Summary:     &summary,
+			Text:        &details,
+			Annotations: annotations,
+		},
+	}
+}
+
+// createHoldCheck fails while a Pull Request is held so it can't be merged
+func createHoldCheck(
+	headSHA string,
+	sender string,
+	reason string,
+	held bool,
+) github.CreateCheckRunOptions {
+	now := github.Timestamp{Time: time.Now()}
+	status := completed
+	conclusion := success
+	title := "Not Held"
+	summary := fmt.Sprintf("@%v removed the hold", sender)
+	text := "This Pull Request can be merged"
+	if held {
+		conclusion = failure
+		title = "Held"
+		summary = fmt.Sprintf("@%v put this Pull Request on hold", sender)
+		text = "Comment `/unhold` to allow this Pull Request to be merged"
+		if reason != "" {
+			text = fmt.Sprintf("Reason: %v\n\n%v", reason, text)
+		}
+	}
+	return github.CreateCheckRunOptions{
+		Name:        hold,
+		HeadSHA:     headSHA,
+		Status:      &status,
+		Conclusion:  &conclusion,
+		StartedAt:   &now,
+		CompletedAt: &now,
+		Output: &github.CheckRunOutput{
+			Title:   &title,
+			Summary: &summary,
+			Text:    &text,
+		},
+	}
+}
+
 func updateUnsuccessfulDCOCheck(
 	check *github.CheckRun,
 ) github.UpdateCheckRunOptions {
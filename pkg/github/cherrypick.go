@@ -0,0 +1,266 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	cherryPickLabelPrefix     = "cherry-pick/"
+	cherryPickBranch          = "paul/cherry-pick-%d-to-%v"
+	cherryPickPendingMessage  = "This Pull Request will be cherry-picked into %v once it is merged"
+	cherryPickOpenedMessage   = "Cherry-picked into %v in #%d"
+	cherryPickNoBranchMessage = "Can not cherry-pick into %v, the branch does not exist"
+	cherryPickExistsMessage   = "Can not cherry-pick into %v, the branch %v already exists"
+	cherryPickConflictMessage = "Can not cherry-pick into %v, commit %v does not apply cleanly and will need to be cherry-picked manually"
+	cherryPickedFromSuffix    = "\n\n(cherry picked from commit %v)"
+)
+
+var errCommitConflict = errors.New("commit does not apply cleanly")
+
+// cherryPickHandler handles the /cherry-pick command, Pull Requests that are
+// not merged yet are labeled and cherry-picked when they are merged
+func cherryPickHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error {
+	if !event.Issue.IsPullRequest() ||
+		len(args) != 1 ||
+		!checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		return nil
+	}
+	target := args[0]
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, event.Issue.GetNumber())
+	if err != nil {
+		return err
+	}
+	if pr.GetMerged() {
+		message, err := cherryPick(ctx, client, pr, target)
+		if err != nil {
+			return err
+		}
+		return createIssueComment(ctx, event, client, message)
+	}
+	if pr.GetState() == closed {
+		return nil
+	}
+	_, res, err := client.Repositories.GetBranch(ctx, owner, repo, target, false)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return createIssueComment(ctx, event, client, fmt.Sprintf(cherryPickNoBranchMessage, target))
+	}
+	if err != nil {
+		return err
+	}
+	label := cherryPickLabelPrefix + target
+	err = syncLabels(ctx, client, owner, repo, nil, []*github.Label{labelByName(label)})
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Issues.AddLabelsToIssue(
+		ctx,
+		owner,
+		repo,
+		pr.GetNumber(),
+		[]string{label},
+	)
+	if err != nil {
+		return err
+	}
+	return createIssueComment(ctx, event, client, fmt.Sprintf(cherryPickPendingMessage, target))
+}
+
+// cherryPickCheck cherry-picks merged Pull Requests into the
+// branches they were labeled with by /cherry-pick
+func cherryPickCheck(
+	ctx context.Context,
+	client *github.Client,
+	event *github.PullRequestEvent,
+) error {
+	pr := event.PullRequest
+	if event.GetAction() != "closed" || !pr.GetMerged() {
+		return nil
+	}
+	for _, label := range pr.Labels {
+		if !strings.HasPrefix(label.GetName(), cherryPickLabelPrefix) {
+			continue
+		}
+		target := strings.TrimPrefix(label.GetName(), cherryPickLabelPrefix)
+		message, err := cherryPick(ctx, client, pr, target)
+		if err != nil {
+			return err
+		}
+		err = createCommentOnce(
+			ctx,
+			client,
+			pr.Base.Repo.Owner.GetLogin(),
+			pr.Base.Repo.GetName(),
+			pr.GetNumber(),
+			message,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cherryPick applies the commits of a Pull Request to a new branch from
+// the target and opens a Pull Request for it, returning the message to
+// report back with
+func cherryPick(
+	ctx context.Context,
+	client *github.Client,
+	pr *github.PullRequest,
+	target string,
+) (string, error) {
+	owner := pr.Base.Repo.Owner.GetLogin()
+	repo := pr.Base.Repo.GetName()
+	targetRef, res, err := client.Git.GetRef(ctx, owner, repo, "heads/"+target)
+	if res != nil && res.StatusCode == http.StatusNotFound {
+		return fmt.Sprintf(cherryPickNoBranchMessage, target), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	commits, err := listPullRequestCommits(ctx, client, owner, repo, pr.GetNumber())
+	if err != nil {
+		return "", err
+	}
+	branch := fmt.Sprintf(cherryPickBranch, pr.GetNumber(), target)
+	_, res, err = client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: targetRef.Object.SHA},
+	})
+	if res != nil && res.StatusCode == http.StatusUnprocessableEntity {
+		return fmt.Sprintf(cherryPickExistsMessage, target, branch), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	head := targetRef.Object.GetSHA()
+	for _, commit := range commits {
+		// merge commits only bring in changes from other branches
+		if len(commit.Parents) != 1 {
+			continue
+		}
+		suffix := fmt.Sprintf(cherryPickedFromSuffix, commit.GetSHA())
+		head, err = applyCommit(ctx, client, owner, repo, branch, head, commit, suffix)
+		if errors.Is(err, errCommitConflict) {
+			message := fmt.Sprintf(cherryPickConflictMessage, target, commit.GetSHA())
+			return message, deleteBranch(ctx, client, owner, repo, branch)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	backport, _, err := client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.String(fmt.Sprintf("[%v] %v", target, pr.GetTitle())),
+		Head:  github.String(branch),
+		Base:  github.String(target),
+		Body:  github.String(fmt.Sprintf("Cherry-pick of #%d into %v", pr.GetNumber(), target)),
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(cherryPickOpenedMessage, target, backport.GetNumber()), nil
+}
+
+// applyCommit cherry-picks a commit on top of head, the Git Data API has no
+// cherry-pick so the commit is merged into a copy of head that has the
+// commit's parent as its parent, leaving only the commit's changes. The
+// suffix is added to the end of the commit's message
+func applyCommit(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, branch, head string,
+	commit *github.RepositoryCommit,
+	suffix string,
+) (string, error) {
+	base, _, err := client.Git.GetCommit(ctx, owner, repo, head)
+	if err != nil {
+		return "", err
+	}
+	sibling, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String("Cherry-pick " + commit.GetSHA()),
+		Tree:    base.Tree,
+		Parents: []*github.Commit{{SHA: commit.Parents[0].SHA}},
+	})
+	if err != nil {
+		return "", err
+	}
+	err = updateBranch(ctx, client, owner, repo, branch, sibling.GetSHA())
+	if err != nil {
+		return "", err
+	}
+	merge, res, err := client.Repositories.Merge(ctx, owner, repo, &github.RepositoryMergeRequest{
+		Base: github.String(branch),
+		Head: commit.SHA,
+	})
+	if res != nil && res.StatusCode == http.StatusConflict {
+		return "", errCommitConflict
+	}
+	if err != nil {
+		return "", err
+	}
+	// nothing is merged when the changes are already on the branch
+	tree := base.Tree
+	if res.StatusCode != http.StatusNoContent {
+		tree = merge.Commit.Tree
+	}
+	picked, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message: github.String(commit.Commit.GetMessage() + suffix),
+		Tree:    tree,
+		Parents: []*github.Commit{{SHA: github.String(head)}},
+		Author:  commit.Commit.Author,
+	})
+	if err != nil {
+		return "", err
+	}
+	return picked.GetSHA(), updateBranch(ctx, client, owner, repo, branch, picked.GetSHA())
+}
+
+// updateBranch force updates a branch to the commit
+func updateBranch(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, branch, sha string,
+) error {
+	_, _, err := client.Git.UpdateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	}, true)
+	return err
+}
+
+// listPullRequestCommits returns every commit in a Pull Request
+func listPullRequestCommits(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	number int,
+) ([]*github.RepositoryCommit, error) {
+	var commits []*github.RepositoryCommit
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, res, err := client.PullRequests.ListCommits(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, page...)
+		if res.NextPage == 0 {
+			return commits, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleCherryPickPullRequest(mux *http.ServeMux, merged bool) {
+	mux.HandleFunc("/repos/Spazzy757/paul/pulls/9", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{
+		  "number": 9,
+		  "title": "Fix the thing",
+		  "state": "open",
+		  "merged": %v,
+		  "base": {"repo": {"name": "paul", "owner": {"login": "Spazzy757"}}}
+		}`, merged)
+	})
+}
+
+// handleCherryPick mocks cherry-picking a Pull Request with one commit and
+// a merge commit into release-1.2 and returns the updates to the branch
+func handleCherryPick(t *testing.T, mux *http.ServeMux) *[]string {
+	mux.HandleFunc("/repos/Spazzy757/paul/git/ref/heads/release-1.2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ref": "refs/heads/release-1.2", "object": {"sha": "release"}}`)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+		  {"sha": "one", "parents": [{"sha": "main"}], "commit": {"message": "Fix the thing", "author": {"name": "Spazzy"}}},
+		  {"sha": "update", "parents": [{"sha": "one"}, {"sha": "main2"}], "commit": {"message": "Merge main"}}
+		]`)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		v := new(github.Reference)
+		_ = json.NewDecoder(r.Body).Decode(v)
+		assert.Equal(t, "refs/heads/paul/cherry-pick-9-to-release-1.2", v.GetRef())
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/git/commits/release", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "release", "tree": {"sha": "release-tree"}}`)
+	})
+	mux.HandleFunc("/repos/Spazzy757/paul/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		v := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&v)
+		if v["parents"].([]interface{})[0] == "main" {
+			assert.Equal(t, "release-tree", v["tree"])
+			fmt.Fprint(w, `{"sha": "sibling"}`)
+			return
+		}
+		assert.Equal(t, []interface{}{"release"}, v["parents"])
+		assert.Equal(t, "merged-tree", v["tree"])
+		assert.Equal(t, "Fix the thing\n\n(cherry picked from commit one)", v["message"])
+		assert.Equal(t, "Spazzy", v["author"].(map[string]interface{})["name"])
+		fmt.Fprint(w, `{"sha": "picked"}`)
+	})
+	updates := []string{}
+	mux.HandleFunc("/repos/Spazzy757/paul/git/refs/heads/paul/cherry-pick-9-to-release-1.2", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			updates = append(updates, "deleted")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		v := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&v)
+		assert.Equal(t, true, v["force"])
+		updates = append(updates, v["sha"].(string))
+		fmt.Fprint(w, `{}`)
+	})
+	return &updates
+}
+
+func TestCherryPickHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"Spazzy757"}}
+	t.Run("Test Merged Pull Request Is Cherry-picked", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleCherryPickPullRequest(mux, true)
+		updates := handleCherryPick(t, mux)
+		mux.HandleFunc("/repos/Spazzy757/paul/merges", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.RepositoryMergeRequest)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "paul/cherry-pick-9-to-release-1.2", v.GetBase())
+			assert.Equal(t, "one", v.GetHead())
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "merge", "commit": {"tree": {"sha": "merged-tree"}}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.NewPullRequest)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "[release-1.2] Fix the thing", v.GetTitle())
+			assert.Equal(t, "release-1.2", v.GetBase())
+			assert.Equal(t, "paul/cherry-pick-9-to-release-1.2", v.GetHead())
+			assert.Equal(t, "Cherry-pick of #9 into release-1.2", v.GetBody())
+			fmt.Fprint(w, `{"number": 10}`)
+		})
+		commented := handleExpectedComment(t, mux, "Cherry-picked into release-1.2 in #10")
+		err := cherryPickHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"release-1.2"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"sibling", "picked"}, *updates)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Conflict Is Reported", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleCherryPickPullRequest(mux, true)
+		updates := handleCherryPick(t, mux)
+		mux.HandleFunc("/repos/Spazzy757/paul/merges", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message": "Merge conflict"}`)
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(cherryPickConflictMessage, "release-1.2", "one"))
+		err := cherryPickHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"release-1.2"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"sibling", "deleted"}, *updates)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Missing Branch Is Reported", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleCherryPickPullRequest(mux, true)
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(cherryPickNoBranchMessage, "release-9"))
+		err := cherryPickHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"release-9"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Open Pull Request Is Labeled", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleCherryPickPullRequest(mux, false)
+		mux.HandleFunc("/repos/Spazzy757/paul/branches/release-1.2", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name": "release-1.2"}`)
+		})
+		created := false
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			v := new(github.Label)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "cherry-pick/release-1.2", v.GetName())
+			created = true
+			fmt.Fprint(w, `{}`)
+		})
+		labeled := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			var v []string
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, []string{"cherry-pick/release-1.2"}, v)
+			labeled = true
+			fmt.Fprint(w, `[]`)
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(cherryPickPendingMessage, "release-1.2"))
+		err := cherryPickHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"release-1.2"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, created)
+		assert.Equal(t, true, labeled)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Open Pull Request With Missing Branch Is Not Labeled", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleCherryPickPullRequest(mux, false)
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("missing branch should not be labeled")
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(cherryPickNoBranchMessage, "release-9"))
+		err := cherryPickHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"release-9"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Non Maintainers Can Not Cherry-pick", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := cherryPickHandler(context.Background(), &types.PaulConfig{}, getHoldMockEvent(t), mClient, []string{"release-1.2"})
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestCherryPickCheck(t *testing.T) {
+	t.Run("Test Labeled Pull Request Is Cherry-picked On Merge", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		updates := handleCherryPick(t, mux)
+		mux.HandleFunc("/repos/Spazzy757/paul/merges", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "merge", "commit": {"tree": {"sha": "merged-tree"}}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"number": 10}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			v := new(github.IssueComment)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "Cherry-picked into release-1.2 in #10", v.GetBody())
+			fmt.Fprint(w, `{}`)
+		})
+		pr := getApprovalsPullRequest()
+		pr.Merged = github.Bool(true)
+		pr.Labels = []*github.Label{{Name: github.String("cherry-pick/release-1.2")}, {Name: github.String("bug")}}
+		err := cherryPickCheck(context.Background(), mClient, &github.PullRequestEvent{
+			Action:      github.String("closed"),
+			PullRequest: pr,
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"sibling", "picked"}, *updates)
+	})
+}
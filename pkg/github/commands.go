@@ -0,0 +1,99 @@
+package github
+
+import (
+	"context"
+
+	"github.com/Spazzy757/paul/pkg/animals"
+	"github.com/Spazzy757/paul/pkg/gif"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+// commandHandler runs a command with the args given after it
+type commandHandler func(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error
+
+// commandHandlers maps each of types.BuiltinCommands to the handler that
+// runs it, the label prefix commands depend on the config so are handled
+// by IssueCommentHandler
+var commandHandlers = map[string]commandHandler{
+	// i.e /cat
+	"cat": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, _ []string) error {
+		if !cfg.PullRequests.CatsEnabled {
+			return nil
+		}
+		return catsHandler(ctx, event, client, animals.NewCatClient())
+	},
+	// i.e /dog
+	"dog": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, _ []string) error {
+		if !cfg.PullRequests.DogsEnabled {
+			return nil
+		}
+		return dogsHandler(ctx, event, client, animals.NewDogClient())
+	},
+	// i.e /giphy cheers
+	"giphy": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		if !cfg.PullRequests.GiphyEnabled || len(args) == 0 {
+			return nil
+		}
+		return giphyHandler(ctx, event, client, gif.NewGifClient(), args)
+	},
+	// i.e /label bug, "good first issue"
+	"label": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return labelHandler(ctx, cfg, event, client, parseLabels(args))
+	},
+	"remove-label": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return removeLabelHandler(ctx, cfg, event, client, parseLabels(args))
+	},
+	// i.e /milestone v1.2
+	"milestone": milestoneHandler,
+	// i.e /priority high
+	"priority": priorityHandler,
+	// i.e /cherry-pick release-1.2
+	"cherry-pick":      cherryPickHandler,
+	"update-branch":    withoutArgs(updateBranchHandler),
+	"rebase":           withoutArgs(rebaseHandler),
+	"retitle":          retitleHandler,
+	"edit-description": withoutArgs(editDescriptionHandler),
+	"approve":          withoutArgs(approveHandler),
+	// "/lgtm cancel" takes it back
+	"lgtm":   lgtmHandler,
+	"retest": withoutArgs(retestHandler),
+	"close": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, _ []string) error {
+		return stateHandler(ctx, cfg, event, client, closed)
+	},
+	"reopen": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, _ []string) error {
+		return stateHandler(ctx, cfg, event, client, open)
+	},
+	// the rest of the comment is the reason
+	"lock": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return lockHandler(ctx, cfg, event, client, true, args)
+	},
+	"unlock": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return lockHandler(ctx, cfg, event, client, false, args)
+	},
+	// the rest of the comment is the reason
+	"hold": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return holdHandler(ctx, cfg, event, client, true, args)
+	},
+	"unhold": func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, args []string) error {
+		return holdHandler(ctx, cfg, event, client, false, args)
+	},
+	"merge": withoutArgs(mergeHandler),
+	// i.e /assign Spazzy757
+	"assign": assignHandler,
+}
+
+// withoutArgs is used for handlers of commands that don't take args
+func withoutArgs(
+	handler func(context.Context, *types.PaulConfig, *github.IssueCommentEvent, *github.Client) error,
+) commandHandler {
+	return func(ctx context.Context, cfg *types.PaulConfig, event *github.IssueCommentEvent, client *github.Client, _ []string) error {
+		return handler(ctx, cfg, event, client)
+	}
+}
@@ -0,0 +1,49 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandHandlers(t *testing.T) {
+	t.Run("Test Every Built-in Command Has A Handler", func(t *testing.T) {
+		for _, command := range types.BuiltinCommands {
+			_, ok := commandHandlers[command]
+			assert.Equal(t, true, ok, command)
+		}
+	})
+	t.Run("Test Every Handler Is A Built-in Command", func(t *testing.T) {
+		for command := range commandHandlers {
+			assert.Equal(t, true, types.IsBuiltinCommand(command), command)
+		}
+	})
+}
+
+func TestGiphyCommand(t *testing.T) {
+	cfg := &types.PaulConfig{PullRequests: types.PullRequests{GiphyEnabled: true}}
+	tests := []struct {
+		name    string
+		comment string
+	}{
+		{name: "Test Giphy Without A Search Term Does Nothing", comment: "/giphy"},
+		{name: "Test Giphy With Trailing Space Does Nothing", comment: "/giphy "},
+		{name: "Test Search Term On The Next Line Is Ignored", comment: "/giphy\ncheers"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mClient, mux, _, teardown := test.GetMockClient()
+			defer teardown()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+			})
+			cmd, args := getCommand(tc.comment)
+			err := commandHandlers[cmd](context.Background(), cfg, getHoldMockEvent(t), mClient, args)
+			assert.Equal(t, nil, err)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+// retitleHandler handles the /retitle command i.e /retitle fix: typo in docs
+func retitleHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error {
+	title := strings.TrimSpace(strings.Join(args, " "))
+	if title == "" || !isAuthorOrMaintainer(cfg, event) {
+		return nil
+	}
+	_, _, err := client.Issues.Edit(
+		ctx,
+		event.Repo.Owner.GetLogin(),
+		event.Repo.GetName(),
+		event.Issue.GetNumber(),
+		&github.IssueRequest{Title: github.String(title)},
+	)
+	return err
+}
+
+// editDescriptionHandler handles the /edit-description command, everything
+// after the command becomes the description, without anything after it the
+// description template is used
+func editDescriptionHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+) error {
+	if !isAuthorOrMaintainer(cfg, event) {
+		return nil
+	}
+	// the command can be an alias so everything after its first word is used
+	var description string
+	body := strings.TrimSpace(event.Comment.GetBody())
+	if i := strings.IndexFunc(body, unicode.IsSpace); i != -1 {
+		description = strings.TrimSpace(body[i:])
+	}
+	if description == "" {
+		description = cfg.EmptyDescriptionCheck.Template
+	}
+	if description == "" {
+		return nil
+	}
+	return editDescription(
+		ctx,
+		client,
+		event.Repo.Owner.GetLogin(),
+		event.Repo.GetName(),
+		event.Issue.GetNumber(),
+		description,
+	)
+}
+
+// editDescription sets the description of an issue/pull request
+func editDescription(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	number int,
+	description string,
+) error {
+	_, _, err := client.Issues.Edit(
+		ctx,
+		owner,
+		repo,
+		number,
+		&github.IssueRequest{Body: github.String(description)},
+	)
+	return err
+}
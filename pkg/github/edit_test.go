@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleIssueEdit(t *testing.T, mux *http.ServeMux, expected *github.IssueRequest) *bool {
+	edited := false
+	mux.HandleFunc("/repos/Spazzy757/paul/issues/9", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		v := new(github.IssueRequest)
+		_ = json.NewDecoder(r.Body).Decode(v)
+		assert.Equal(t, expected, v)
+		edited = true
+		fmt.Fprint(w, `{}`)
+	})
+	return &edited
+}
+
+func TestRetitleHandler(t *testing.T) {
+	cfg := &types.PaulConfig{}
+	t.Run("Test Author Can Retitle", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		edited := handleIssueEdit(t, mux, &github.IssueRequest{Title: github.String("fix: typo in docs")})
+		err := retitleHandler(
+			context.Background(),
+			cfg,
+			getHoldMockEvent(t),
+			mClient,
+			[]string{"fix:", "typo", "in", "docs"},
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *edited)
+	})
+	t.Run("Test Others Can Not Retitle", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("someone")
+		err := retitleHandler(context.Background(), cfg, event, mClient, []string{"spam"})
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestEditDescriptionHandler(t *testing.T) {
+	cfg := &types.PaulConfig{
+		EmptyDescriptionCheck: types.EmptyDescriptionCheck{Template: "## What\n\n## Why\n"},
+	}
+	t.Run("Test Description Is Replaced", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		edited := handleIssueEdit(t, mux, &github.IssueRequest{Body: github.String("Fixes the thing\n\nand more")})
+		event := getHoldMockEvent(t)
+		event.Comment.Body = github.String("/edit-description Fixes the thing\n\nand more")
+		err := editDescriptionHandler(context.Background(), cfg, event, mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *edited)
+	})
+	t.Run("Test Alias Is Not Part Of The Description", func(t *testing.T) {
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/contents/", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{
+			  "type": "file",
+			  "name": "PAUL.yaml",
+			  "download_url": "`+serverURL+baseURLPath+`/download/PAUL.yaml"
+			}]`)
+		})
+		mux.HandleFunc("/download/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "custom_commands:\n  ed:\n    alias: /edit-description\n")
+		})
+		edited := handleIssueEdit(t, mux, &github.IssueRequest{Body: github.String("Fixes the thing")})
+		event := getHoldMockEvent(t)
+		event.Comment.Body = github.String("/ed Fixes the thing")
+		err := IssueCommentHandler(context.Background(), event, mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *edited)
+	})
+	t.Run("Test Template Is Used", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		edited := handleIssueEdit(t, mux, &github.IssueRequest{Body: github.String("## What\n\n## Why\n")})
+		event := getHoldMockEvent(t)
+		event.Comment.Body = github.String("/edit-description")
+		err := editDescriptionHandler(context.Background(), cfg, event, mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *edited)
+	})
+}
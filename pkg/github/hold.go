@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	holdLabel          = "do-not-merge/hold"
+	heldMergeMessage   = "This Pull Request is on hold, comment `/unhold` before merging"
+	heldSkippedMessage = "Paul did not merge this Pull Request because it is on hold, comment `/unhold` to allow it to be merged"
+)
+
+// holdHandler handles the /hold and /unhold commands
+func holdHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	held bool,
+	args []string,
+) error {
+	if !event.Issue.IsPullRequest() ||
+		!checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	number := event.Issue.GetNumber()
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		return err
+	}
+	if held {
+		err = syncLabels(ctx, client, owner, repo, nil, []*github.Label{labelByName(holdLabel)})
+		if err != nil {
+			return err
+		}
+		_, _, err = client.Issues.AddLabelsToIssue(ctx, owner, repo, number, []string{holdLabel})
+	} else {
+		var res *github.Response
+		res, err = client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, url.PathEscape(holdLabel))
+		// the Pull Request was not held
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Checks.CreateCheckRun(
+		ctx,
+		owner,
+		repo,
+		createHoldCheck(
+			pr.Head.GetSHA(),
+			event.Sender.GetLogin(),
+			strings.Join(args, " "),
+			held,
+		),
+	)
+	return err
+}
+
+// holdCheck keeps the hold check failing on new commits to a held Pull Request
+func holdCheck(
+	ctx context.Context,
+	client *github.Client,
+	event *github.PullRequestEvent,
+) error {
+	if event.GetAction() != "synchronize" || !isHeld(event.PullRequest.Labels) {
+		return nil
+	}
+	pr := event.PullRequest
+	_, _, err := client.Checks.CreateCheckRun(
+		ctx,
+		pr.Base.Repo.Owner.GetLogin(),
+		pr.Base.Repo.GetName(),
+		createHoldCheck(pr.Head.GetSHA(), event.Sender.GetLogin(), "", true),
+	)
+	return err
+}
+
+// isHeld checks if the labels include the hold label
+func isHeld(labels []*github.Label) bool {
+	for _, label := range labels {
+		if label.GetName() == holdLabel {
+			return true
+		}
+	}
+	return false
+}
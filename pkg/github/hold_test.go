@@ -0,0 +1,224 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func getHoldMockEvent(t *testing.T) *github.IssueCommentEvent {
+	webhookPayload := getIssueCommentMockPayload("merge-command")
+	req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+	req.Header.Set("X-GitHub-Event", "issue_comment")
+	event, err := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+	assert.Equal(t, nil, err)
+	return event.(*github.IssueCommentEvent)
+}
+
+func handleHoldPullRequest(mux *http.ServeMux, labels string) {
+	mux.HandleFunc(
+		"/repos/Spazzy757/paul/pulls/9",
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{
+			  "number": 9,
+			  "mergeable": true,
+			  "labels": %v,
+			  "head": {"sha": "deadbeef"},
+			  "base": {"repo": {"name": "paul", "owner": {"login": "Spazzy757"}}}
+			}`, labels)
+		},
+	)
+}
+
+func TestHoldHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"Spazzy757"}}
+	t.Run("Test Hold Labels And Fails Check", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[]`)
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[{"name":"do-not-merge/hold"}]`)
+				return
+			}
+			t.Errorf("hold label should not be created again")
+		})
+		labeled := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			var v []string
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, []string{holdLabel}, v)
+			labeled = true
+			fmt.Fprint(w, `[]`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			v := new(github.CreateCheckRunOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, hold, v.Name)
+			assert.Equal(t, "deadbeef", v.HeadSHA)
+			assert.Equal(t, failure, v.GetConclusion())
+			assert.Contains(t, v.Output.GetText(), "Reason: waiting on the release")
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		err := holdHandler(
+			context.Background(),
+			cfg,
+			getHoldMockEvent(t),
+			mClient,
+			true,
+			[]string{"waiting", "on", "the", "release"},
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, labeled)
+	})
+	t.Run("Test Unhold Removes Label And Passes Check", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[{"name":"do-not-merge/hold"}]`)
+		unlabeled := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels/do-not-merge/hold", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "/repos/Spazzy757/paul/issues/9/labels/do-not-merge%2Fhold", r.URL.EscapedPath())
+			unlabeled = true
+			fmt.Fprint(w, `[]`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.CreateCheckRunOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, success, v.GetConclusion())
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		err := holdHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, false, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, unlabeled)
+	})
+	t.Run("Test Non Maintainers Can Not Hold", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := holdHandler(
+			context.Background(),
+			&types.PaulConfig{},
+			getHoldMockEvent(t),
+			mClient,
+			true,
+			nil,
+		)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestHoldCheck(t *testing.T) {
+	t.Run("Test New Commits Keep The Hold", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		created := false
+		mux.HandleFunc("/repos/Spazzy757/paul/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.CreateCheckRunOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, failure, v.GetConclusion())
+			created = true
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		event := &github.PullRequestEvent{
+			Action: github.String("synchronize"),
+			Sender: &github.User{Login: github.String("Spazzy757")},
+			PullRequest: &github.PullRequest{
+				Labels: []*github.Label{{Name: github.String(holdLabel)}},
+				Head:   &github.PullRequestBranch{SHA: github.String("deadbeef")},
+				Base: &github.PullRequestBranch{
+					Repo: &github.Repository{
+						Name:  github.String("paul"),
+						Owner: &github.User{Login: github.String("Spazzy757")},
+					},
+				},
+			},
+		}
+		err := holdCheck(context.Background(), mClient, event)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, created)
+	})
+	t.Run("Test Pull Requests Without Hold Are Ignored", func(t *testing.T) {
+		event := &github.PullRequestEvent{
+			Action:      github.String("synchronize"),
+			PullRequest: &github.PullRequest{},
+		}
+		err := holdCheck(context.Background(), nil, event)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestMergeHeldPullRequest(t *testing.T) {
+	cfg := types.PaulConfig{
+		Maintainers:  []string{"Spazzy757"},
+		PullRequests: types.PullRequests{AutomatedMerge: true},
+	}
+	t.Run("Test Merge Command Refuses Held Pull Request", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[{"name":"do-not-merge/hold"}]`)
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/merge", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("held pull request should not be merged")
+		})
+		commented := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.IssueComment)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, heldMergeMessage, v.GetBody())
+			commented = true
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		err := mergeHandler(context.Background(), &cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, commented)
+	})
+	t.Run("Test Automated Merge Skips Held Pull Request And Comments Once", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/merge", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("held pull request should not be merged")
+		})
+		comments := `[]`
+		created := 0
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, comments)
+				return
+			}
+			created++
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		pr := &github.PullRequest{
+			Number: github.Int(9),
+			Labels: []*github.Label{
+				{Name: github.String(mergeLabel)},
+				{Name: github.String(holdLabel)},
+			},
+			Base: &github.PullRequestBranch{
+				Repo: &github.Repository{
+					Name:  github.String("paul"),
+					Owner: &github.User{Login: github.String("Spazzy757")},
+				},
+			},
+		}
+		informationList := []*ScehduledJobInformation{{
+			Cfg:          cfg,
+			PullRequests: []*github.PullRequest{pr},
+		}}
+		mergePendingPullRequests(context.Background(), mClient, informationList)
+		comments = fmt.Sprintf(`[{"body":%q,"user":{"login":"paul[bot]","type":"Bot"}}]`, heldSkippedMessage)
+		mergePendingPullRequests(context.Background(), mClient, informationList)
+		assert.Equal(t, 1, created)
+	})
+}
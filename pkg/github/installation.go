@@ -0,0 +1,184 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	paulclient "github.com/Spazzy757/paul/pkg/client"
+	"github.com/Spazzy757/paul/pkg/config"
+	"github.com/google/go-github/v49/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	bootstrapBranch  = "paul/bootstrap"
+	bootstrapTitle   = "Add Paul configuration"
+	bootstrapMessage = "Add .github/PAUL.yaml"
+	bootstrapBody    = `Thanks for installing Paul :alien:
+
+This adds a starter ` + "`.github/PAUL.yaml`" + `, have a look at the
+[configuration docs](https://github.com/Spazzy757/paul#configuration)
+for everything Paul can do and merge when you are happy with it.`
+	starterConfig = `# See https://github.com/Spazzy757/paul#configuration
+maintainers:
+  - %v
+# Allows for the /label and /remove-label commands
+labels: true
+pull_requests:
+  # Enables the /assign command
+  assign: true
+  # Merges Pull Requests labeled with merge once they are mergeable
+  automated_merge: false
+  # Enables DCO check on commits
+  dco_check: false
+`
+)
+
+// bootstrap is set when new installations should be set up
+var bootstrap bool
+
+// EnableBootstrap makes Paul create its labels and open a Pull Request
+// adding a starter PAUL.yaml to repositories it is installed on
+func EnableBootstrap() {
+	bootstrap = true
+}
+
+// RequiresClient reports if an event needs an installation client, events
+// for installations that were removed can not get an access token
+func RequiresClient(eventType, action string) bool {
+	return eventType != "installation" || (action != "deleted" && action != "suspend")
+}
+
+// InstallationHandler handler for the installation event
+func InstallationHandler(
+	ctx context.Context,
+	event *github.InstallationEvent,
+	client *github.Client,
+) error {
+	switch event.GetAction() {
+	case "created":
+		return bootstrapRepositories(
+			ctx,
+			client,
+			event.Installation.Account.GetLogin(),
+			event.Sender.GetLogin(),
+			event.Repositories,
+		)
+	case "deleted", "suspend":
+		paulclient.ForgetInstallation(event.Installation.GetID())
+		config.InvalidateOwner(event.Installation.Account.GetLogin())
+	}
+	return nil
+}
+
+// InstallationRepositoriesHandler handler for the installation_repositories event
+func InstallationRepositoriesHandler(
+	ctx context.Context,
+	event *github.InstallationRepositoriesEvent,
+	client *github.Client,
+) error {
+	owner := event.Installation.Account.GetLogin()
+	switch event.GetAction() {
+	case "added":
+		return bootstrapRepositories(
+			ctx,
+			client,
+			owner,
+			event.Sender.GetLogin(),
+			event.RepositoriesAdded,
+		)
+	case "removed":
+		for _, repo := range event.RepositoriesRemoved {
+			config.InvalidateCache(owner, repo.GetName())
+		}
+	}
+	return nil
+}
+
+// bootstrapRepositories sets up each repository, a failure in one
+// repository does not stop the others from being set up
+func bootstrapRepositories(
+	ctx context.Context,
+	client *github.Client,
+	owner, sender string,
+	repos []*github.Repository,
+) error {
+	if !bootstrap {
+		return nil
+	}
+	failed := []string{}
+	for _, repo := range repos {
+		err := bootstrapRepository(ctx, client, owner, repo.GetName(), sender)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"error": err.Error(),
+				"owner": owner,
+				"repo":  repo.GetName(),
+			}).Error("bootstrapping repository failed")
+			failed = append(failed, repo.GetName())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("bootstrapping failed for: %v", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// bootstrapRepository creates Paul's labels and opens a Pull Request
+// with a starter config when the repository does not have one
+func bootstrapRepository(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, sender string,
+) error {
+	repository, _, err := client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		return err
+	}
+	if err = syncLabels(ctx, client, owner, repo, nil, defaultLabels); err != nil {
+		return err
+	}
+	defaultBranch := repository.GetDefaultBranch()
+	hasConfig, err := config.HasConfig(ctx, owner, repo, defaultBranch, client)
+	if err != nil || hasConfig {
+		return err
+	}
+	ref, _, err := client.Git.GetRef(ctx, owner, repo, "heads/"+defaultBranch)
+	if err != nil {
+		return err
+	}
+	_, res, err := client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + bootstrapBranch),
+		Object: &github.GitObject{SHA: ref.Object.SHA},
+	})
+	// The branch exists when Paul was installed before, leave it be
+	if res != nil && res.StatusCode == http.StatusUnprocessableEntity {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, _, err = client.Repositories.CreateFile(
+		ctx,
+		owner,
+		repo,
+		".github/PAUL.yaml",
+		&github.RepositoryContentFileOptions{
+			Message: github.String(bootstrapMessage),
+			Content: []byte(fmt.Sprintf(starterConfig, sender)),
+			Branch:  github.String(bootstrapBranch),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	_, _, err = client.PullRequests.Create(ctx, owner, repo, &github.NewPullRequest{
+		Title: github.String(bootstrapTitle),
+		Head:  github.String(bootstrapBranch),
+		Base:  github.String(defaultBranch),
+		Body:  github.String(bootstrapBody),
+	})
+	return err
+}
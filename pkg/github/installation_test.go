@@ -0,0 +1,151 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/config"
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallationHandler(t *testing.T) {
+	webhookPayload := test.GetMockPayload("installation-created")
+	req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+	req.Header.Set("X-GitHub-Event", "installation")
+
+	t.Run("Test Nothing Is Done When Bootstrap Is Disabled", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := IncomingWebhook(context.Background(), req, webhookPayload, mClient)
+		assert.Equal(t, nil, err)
+	})
+	t.Run("Test Repository Is Bootstrapped", func(t *testing.T) {
+		bootstrap = true
+		defer func() { bootstrap = false }()
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"name":"paul","default_branch":"main"}`)
+		})
+		createdLabels := []string{}
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[{"name":"Stale","color":"ffffff"}]`)
+				return
+			}
+			assert.Equal(t, http.MethodPost, r.Method)
+			v := new(github.Label)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			createdLabels = append(createdLabels, v.GetName())
+			fmt.Fprint(w, `{"name":"merge"}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"deadbeef"}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/refs", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			v := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, map[string]string{"ref": "refs/heads/paul/bootstrap", "sha": "deadbeef"}, v)
+			fmt.Fprint(w, `{"ref":"refs/heads/paul/bootstrap"}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/contents/.github/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			v := new(github.RepositoryContentFileOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, bootstrapBranch, v.GetBranch())
+			assert.Empty(t, config.Validate(v.Content))
+			assert.Contains(t, string(v.Content), "- Spazzy757")
+			fmt.Fprint(w, `{}`)
+		})
+		pullRequestCreated := false
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			v := new(github.NewPullRequest)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "main", v.GetBase())
+			assert.Equal(t, bootstrapBranch, v.GetHead())
+			pullRequestCreated = true
+			fmt.Fprint(w, `{"number":1}`)
+		})
+		err := IncomingWebhook(context.Background(), req, webhookPayload, mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"merge", holdLabel, lgtmLabel}, createdLabels)
+		assert.Equal(t, true, pullRequestCreated)
+	})
+	t.Run("Test Existing Bootstrap Branch Is Left Alone", func(t *testing.T) {
+		bootstrap = true
+		defer func() { bootstrap = false }()
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"name":"paul","default_branch":"main"}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"stale"},{"name":"merge"},{"name":"do-not-merge/hold"},{"name":"lgtm"}]`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"ref":"refs/heads/main","object":{"sha":"deadbeef"}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/refs", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Reference already exists"}`)
+		})
+		err := IncomingWebhook(context.Background(), req, webhookPayload, mClient)
+		assert.Equal(t, nil, err)
+	})
+	t.Run("Test Failed Bootstrap Returns Error", func(t *testing.T) {
+		bootstrap = true
+		defer func() { bootstrap = false }()
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		err := IncomingWebhook(context.Background(), req, webhookPayload, mClient)
+		assert.EqualError(t, err, "bootstrapping failed for: paul")
+	})
+	t.Run("Test Deleted Installation Is Cleaned Up Without A Client", func(t *testing.T) {
+		event := &github.InstallationEvent{
+			Action: github.String("deleted"),
+			Installation: &github.Installation{
+				ID:      github.Int64(1),
+				Account: &github.User{Login: github.String("Spazzy757")},
+			},
+		}
+		err := InstallationHandler(context.Background(), event, nil)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestInstallationRepositoriesHandler(t *testing.T) {
+	t.Run("Test Removed Repositories Are Handled", func(t *testing.T) {
+		event := &github.InstallationRepositoriesEvent{
+			Action: github.String("removed"),
+			Installation: &github.Installation{
+				ID:      github.Int64(1),
+				Account: &github.User{Login: github.String("Spazzy757")},
+			},
+			RepositoriesRemoved: []*github.Repository{{Name: github.String("paul")}},
+		}
+		err := InstallationRepositoriesHandler(context.Background(), event, nil)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestRequiresClient(t *testing.T) {
+	assert.Equal(t, true, RequiresClient("pull_request", "deleted"))
+	assert.Equal(t, true, RequiresClient("installation", "created"))
+	assert.Equal(t, false, RequiresClient("installation", "deleted"))
+	assert.Equal(t, false, RequiresClient("installation", "suspend"))
+}
@@ -3,6 +3,9 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/Spazzy757/paul/pkg/animals"
@@ -21,6 +24,11 @@ func IssueCommentHandler(
 	event *github.IssueCommentEvent,
 	client *github.Client,
 ) error {
+	// Paul's own comments, and other bots, don't run commands
+	// as they could end up replying to each other
+	if event.Sender.GetType() == "Bot" {
+		return nil
+	}
 	// load Paul Config from repo
 	cfg, configErr := config.GetPaulConfig(
 		ctx,
@@ -35,47 +43,32 @@ func IssueCommentHandler(
 
 	var err error
 	// Check comments for any commands
-	if *event.Action == "created" {
+	if runsCommand(event) {
 		// Get Comment
 		comment := event.GetComment()
 		// Get Which Command is run
 		// Throw away args as they are not used currently
 		cmd, args := getCommand(*comment.Body)
+		// Resolve aliases and canned responses from custom_commands
+		cmd, response := resolveCommand(&cfg, cmd)
+		handler, builtin := commandHandlers[cmd]
+		removedPrefix, removesPrefix := cfg.Labels.RemovesPrefix(cmd)
 		// Switch statement to handle different commands
 		switch {
-		// Case of /cat command
-		case cmd == "cat" && cfg.PullRequests.CatsEnabled:
-			// Get the Cat Client
-			animalClient := animals.NewCatClient()
-			err = catsHandler(ctx, event, client, animalClient)
-		// Case of /dog command
-		case cmd == "dog" && cfg.PullRequests.DogsEnabled:
-			// Get the Dog Client
-			animalClient := animals.NewDogClient()
-			err = dogsHandler(ctx, event, client, animalClient)
-		case cmd == "giphy" && cfg.PullRequests.GiphyEnabled:
-			// Get the Dog Client
-			giphyClient := gif.NewGifClient()
-			err = giphyHandler(ctx, event, client, giphyClient, args)
-		// Case /label command
-		case cmd == "label":
-			// handle the labels
-			// only a single label can be added at a time
-			// i.e "good first issue"
-			labels := []string{strings.Join(args, " ")}
+		// Case of a built-in command i.e /merge
+		case builtin:
+			err = handler(ctx, &cfg, event, client, args)
+		// Case of a label prefix command i.e /kind bug
+		case cfg.Labels.IsPrefix(cmd):
+			labels := prefixLabels(cmd, parseLabels(args))
 			err = labelHandler(ctx, &cfg, event, client, labels)
-		// Case /remove-label command
-		case cmd == "remove-label":
-			err = removeLabelHandler(ctx, &cfg, event, client, args)
-		// Case /approve command
-		case cmd == "approve":
-			err = approveHandler(ctx, &cfg, event, client)
-		// Case /merge command
-		case cmd == "merge":
-			err = mergeHandler(ctx, &cfg, event, client)
-		// Case /assign command
-		case cmd == "assign":
-			err = assignHandler(ctx, &cfg, event, client, args)
+		// Case of removing a prefixed label i.e /remove-kind bug
+		case removesPrefix:
+			labels := prefixLabels(removedPrefix, parseLabels(args))
+			err = removeLabelHandler(ctx, &cfg, event, client, labels)
+		// Case of a custom command with a canned response
+		case response != "":
+			err = createIssueComment(ctx, event, client, fillPlaceholders(response, event))
 		default:
 			break
 		}
@@ -83,16 +76,61 @@ func IssueCommentHandler(
 	return err
 }
 
-// getCommand strips out the command and any args that are given
+// runsCommand checks if a comment was created or edited to run a different
+// command, edits that leave the command as it was don't run it again
+func runsCommand(event *github.IssueCommentEvent) bool {
+	switch event.GetAction() {
+	case "created":
+		return true
+	case "edited":
+		if event.Changes == nil || event.Changes.Body == nil {
+			return false
+		}
+		cmd, args := getCommand(event.Comment.GetBody())
+		previousCmd, previousArgs := getCommand(event.Changes.Body.GetFrom())
+		return cmd != "" &&
+			(cmd != previousCmd || strings.Join(args, " ") != strings.Join(previousArgs, " "))
+	}
+	return false
+}
+
+// getCommand strips out the command and any args that are given,
+// only the first line of the comment is used
 func getCommand(comment string) (string, []string) {
 	var args []string
 	if !strings.HasPrefix(comment, "/") {
 		return "", args
 	}
-	commands := strings.Split(comment[1:], " ")
+	line := strings.TrimSpace(strings.SplitN(comment, "\n", 2)[0])
+	commands := strings.Split(line[1:], " ")
 	return commands[0], commands[1:]
 }
 
+// resolveCommand returns the command an alias is for and the canned
+// response of a custom command, built-in commands can't be replaced
+func resolveCommand(cfg *types.PaulConfig, cmd string) (string, string) {
+	if cmd == "" || cfg.IsCommand(cmd) {
+		return cmd, ""
+	}
+	custom, ok := cfg.CustomCommands[cmd]
+	if !ok {
+		return cmd, ""
+	}
+	if custom.Alias != "" {
+		return strings.TrimPrefix(custom.Alias, "/"), ""
+	}
+	return cmd, custom.Response
+}
+
+// fillPlaceholders fills in the placeholders a canned response can use
+func fillPlaceholders(response string, event *github.IssueCommentEvent) string {
+	return strings.NewReplacer(
+		"{{sender}}", event.Sender.GetLogin(),
+		"{{number}}", strconv.Itoa(event.Issue.GetNumber()),
+		"{{repo}}", event.Repo.GetFullName(),
+	).Replace(response)
+}
+
 // handler for the /merge command
 func mergeHandler(
 	ctx context.Context,
@@ -111,9 +149,18 @@ func mergeHandler(
 		if err != nil {
 			return err
 		}
-		if pr.GetMergeable() {
+		approvals, err := approvalsMessage(ctx, client, cfg, pr)
+		if err != nil {
+			return err
+		}
+		switch {
+		case isHeld(pr.Labels):
+			err = createIssueComment(ctx, event, client, heldMergeMessage)
+		case approvals != "":
+			err = createIssueComment(ctx, event, client, approvals)
+		case pr.GetMergeable():
 			err = mergePullRequest(ctx, client, pr)
-		} else {
+		default:
 			message := "This Pull Request Can not be merge currently"
 			err = createIssueComment(ctx, event, client, message)
 		}
@@ -213,12 +260,34 @@ func labelHandler(
 	labels []string,
 ) error {
 	var err error
-	if cfg.Labels &&
+	if cfg.Labels.Enabled &&
 		checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		labels, undefined := resolveLabels(cfg.Labels, labels)
+		if len(undefined) > 0 {
+			message := fmt.Sprintf(undefinedLabelsMessage, strings.Join(undefined, ", "))
+			if err = createIssueComment(ctx, event, client, message); err != nil {
+				return err
+			}
+		}
+		if len(labels) == 0 {
+			return nil
+		}
+		// defined labels are created with their color and description
+		// so Github doesn't create them with the defaults
+		var definitions []types.LabelDefinition
+		for _, label := range labels {
+			if definition, ok := cfg.Labels.Lookup(label); ok {
+				definitions = append(definitions, definition)
+			}
+		}
+		owner, repo := event.Repo.Owner.GetLogin(), event.Repo.GetName()
+		if err = syncLabels(ctx, client, owner, repo, definitions, nil); err != nil {
+			return err
+		}
 		_, _, err = client.Issues.AddLabelsToIssue(
 			ctx,
-			event.Repo.Owner.GetLogin(),
-			event.Repo.GetName(),
+			owner,
+			repo,
 			event.Issue.GetNumber(),
 			labels,
 		)
@@ -226,7 +295,8 @@ func labelHandler(
 	return err
 }
 
-// removeLabelHandler handles the /removelabel command
+// removeLabelHandler handles the /remove-label command,
+// labels can be patterns i.e area/*
 func removeLabelHandler(
 	ctx context.Context,
 	cfg *types.PaulConfig,
@@ -234,21 +304,38 @@ func removeLabelHandler(
 	client *github.Client,
 	labels []string,
 ) error {
-	var err error
-	if cfg.Labels &&
-		// handle the remove labels,
-		// if more than one arg is passed through, don't do anything
-		len(labels) == 1 &&
-		checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
-		_, err = client.Issues.RemoveLabelForIssue(
+	if !cfg.Labels.Enabled ||
+		!checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		return nil
+	}
+	var remove []string
+	for _, label := range labels {
+		switch definition, ok := cfg.Labels.Lookup(label); {
+		case isLabelPattern(label):
+			remove = append(remove, matchLabels(label, event.Issue.Labels)...)
+		case ok:
+			remove = append(remove, definition.Name)
+		default:
+			remove = append(remove, label)
+		}
+	}
+	for _, label := range remove {
+		res, err := client.Issues.RemoveLabelForIssue(
 			ctx,
 			event.Repo.Owner.GetLogin(),
 			event.Repo.GetName(),
 			event.Issue.GetNumber(),
-			labels[0],
+			url.PathEscape(label),
 		)
+		// the label is not on the issue so there is nothing to do
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
 	}
-	return err
+	return nil
 }
 
 // approveHandler approves Pull Requests
@@ -258,22 +345,26 @@ func approveHandler(
 	event *github.IssueCommentEvent,
 	client *github.Client,
 ) error {
-	var err error
-	if cfg.PullRequests.AllowApproval &&
-		checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) &&
-		event.Issue.IsPullRequest() {
+	if !checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) ||
+		!event.Issue.IsPullRequest() {
+		return nil
+	}
+	if cfg.PullRequests.AllowApproval {
 		pullRequestReviewRequest := &github.PullRequestReviewRequest{
 			Event: github.String("APPROVE"),
 		}
-		_, _, err = client.PullRequests.CreateReview(
+		_, _, err := client.PullRequests.CreateReview(
 			ctx,
 			event.Repo.Owner.GetLogin(),
 			event.Repo.GetName(),
 			event.Issue.GetNumber(),
 			pullRequestReviewRequest,
 		)
+		if err != nil {
+			return err
+		}
 	}
-	return err
+	return recordApproval(ctx, cfg, event, client)
 }
 
 // createIssueComment sends a comment to an issue/pull request
@@ -294,6 +385,51 @@ func createIssueComment(
 	return err
 }
 
+// createCommentOnce comments on an issue/pull request unless a bot
+// already left the same comment, used by jobs that run repeatedly
+func createCommentOnce(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	number int,
+	message string,
+) error {
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		comments, res, err := client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if comment.User.GetType() == "Bot" && comment.GetBody() == message {
+				return nil
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	_, _, err := client.Issues.CreateComment(
+		ctx,
+		owner,
+		repo,
+		number,
+		&github.IssueComment{Body: &message},
+	)
+	return err
+}
+
+// isAuthorOrMaintainer checks if the comment was made by the author
+// of the issue/pull request or a maintainer
+func isAuthorOrMaintainer(cfg *types.PaulConfig, event *github.IssueCommentEvent) bool {
+	sender := event.Sender.GetLogin()
+	return sender == event.Issue.User.GetLogin() ||
+		checkStringInList(cfg.Maintainers, sender)
+}
+
 // checkStringInList checks if string is in a list of strings
 func checkStringInList(stringList []string, query string) bool {
 	for _, i := range stringList {
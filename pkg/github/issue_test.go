@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/Spazzy757/paul/pkg/animals"
@@ -26,6 +27,20 @@ func getIssueCommentMockPayload(payloadType string) []byte {
 	return []byte(file)
 }
 
+// handleExpectedComment asserts the comment made on the Pull Request and
+// returns if it was made
+func handleExpectedComment(t *testing.T, mux *http.ServeMux, expected string) *bool {
+	commented := false
+	mux.HandleFunc("/repos/Spazzy757/paul/issues/9/comments", func(w http.ResponseWriter, r *http.Request) {
+		v := new(github.IssueComment)
+		_ = json.NewDecoder(r.Body).Decode(v)
+		assert.Equal(t, expected, v.GetBody())
+		commented = true
+		fmt.Fprint(w, `{}`)
+	})
+	return &commented
+}
+
 func TestCreateComment(t *testing.T) {
 	t.Run("Test Issue Comment Webhook is Handled correctly", func(t *testing.T) {
 		mClient, mux, _, teardown := test.GetMockClient()
@@ -207,7 +222,7 @@ func TestHandleLabels(t *testing.T) {
 			Maintainers: []string{
 				"Spazzy757",
 			},
-			Labels: true,
+			Labels: types.Labels{Enabled: true},
 		}
 
 		input := []string{"test"}
@@ -230,6 +245,64 @@ func TestHandleLabels(t *testing.T) {
 		err := labelHandler(context.Background(), cfg, e, mClient, input)
 		assert.Equal(t, nil, err)
 	})
+	t.Run("Test Undefined Labels Are Refused", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		cfg := &types.PaulConfig{
+			Maintainers: []string{
+				"Spazzy757",
+			},
+			Labels: types.Labels{
+				Enabled: true,
+				Definitions: []types.LabelDefinition{
+					{Name: "bug", Aliases: []string{"defect"}, Color: "#D73A4A"},
+				},
+			},
+		}
+		created := false
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/labels",
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "GET" {
+					fmt.Fprint(w, `[]`)
+					return
+				}
+				v := new(github.Label)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assert.Equal(t, "bug", v.GetName())
+				assert.Equal(t, "d73a4a", v.GetColor())
+				created = true
+				fmt.Fprint(w, `{"id":1}`)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/issues/9/labels",
+			func(w http.ResponseWriter, r *http.Request) {
+				var v []string
+				_ = json.NewDecoder(r.Body).Decode(&v)
+				assert.Equal(t, []string{"bug"}, v)
+				fmt.Fprint(w, `[{"url":"u"}]`)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/issues/9/comments",
+			func(w http.ResponseWriter, r *http.Request) {
+				v := new(github.IssueComment)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assert.Equal(t, "These labels are not defined in PAUL.yaml: wontfix", v.GetBody())
+				fmt.Fprint(w, `{"id":1}`)
+			},
+		)
+		webhookPayload := getIssueCommentMockPayload("dog-command")
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+
+		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+		e := event.(*github.IssueCommentEvent)
+		err := labelHandler(context.Background(), cfg, e, mClient, []string{"defect", "wontfix"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, created)
+	})
 }
 
 func TestAssignCommand(t *testing.T) {
@@ -324,6 +397,82 @@ func TestHandleRemoveLabels(t *testing.T) {
 		err := removeLabelHandler(context.Background(), cfg, e, mClient, []string{"test"})
 		assert.Equal(t, nil, err)
 	})
+	t.Run("Test Multiple Labels And Patterns Are Removed", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		cfg := &types.PaulConfig{
+			Maintainers: []string{
+				"Spazzy757",
+			},
+			Labels: types.Labels{
+				Enabled: true,
+				Definitions: []types.LabelDefinition{
+					{Name: "bug", Aliases: []string{"defect"}},
+				},
+			},
+		}
+		removed := []string{}
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/issues/9/labels/",
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, "DELETE")
+				label := strings.TrimPrefix(r.URL.Path, "/repos/Spazzy757/paul/issues/9/labels/")
+				removed = append(removed, label)
+				if label == "missing" {
+					w.WriteHeader(http.StatusNotFound)
+				}
+			},
+		)
+
+		webhookPayload := getIssueCommentMockPayload("removelabel-command")
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+
+		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+		e := event.(*github.IssueCommentEvent)
+		e.Issue.Labels = []*github.Label{
+			{Name: github.String("area/api")},
+			{Name: github.String("area/docs")},
+			{Name: github.String("bug")},
+		}
+		err := removeLabelHandler(
+			context.Background(),
+			cfg,
+			e,
+			mClient,
+			[]string{"defect", "area/*", "missing"},
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"bug", "area/api", "area/docs", "missing"}, removed)
+	})
+	t.Run("Test Labels With Slashes Are Escaped", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		cfg := &types.PaulConfig{
+			Maintainers: []string{
+				"Spazzy757",
+			},
+			Labels: types.Labels{Enabled: true},
+		}
+		removed := []string{}
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/issues/9/labels/",
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, "DELETE")
+				removed = append(removed, r.URL.EscapedPath())
+			},
+		)
+
+		webhookPayload := getIssueCommentMockPayload("removelabel-command")
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+
+		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+		e := event.(*github.IssueCommentEvent)
+		err := removeLabelHandler(context.Background(), cfg, e, mClient, []string{"kind/bug"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"/repos/Spazzy757/paul/issues/9/labels/kind%2Fbug"}, removed)
+	})
 }
 
 func TestCheckStringInList(t *testing.T) {
@@ -357,6 +506,12 @@ func TestGetCommand(t *testing.T) {
 		assert.Equal(t, expectedCommand, cmd)
 		assert.Equal(t, expectedArgs, args)
 	})
+	t.Run("Test Only The First Line Is Used", func(t *testing.T) {
+		comment := "/kind bug\r\nThis is broken in the api"
+		cmd, args := getCommand(comment)
+		assert.Equal(t, "kind", cmd)
+		assert.Equal(t, []string{"bug"}, args)
+	})
 
 }
 
@@ -609,6 +764,17 @@ func TestIssueCommentHandler(t *testing.T) {
 		assert.Equal(t, nil, err)
 	})
 
+	t.Run("Test Bot Comments Are Ignored", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		e := getHoldMockEvent(t)
+		e.Sender.Type = github.String("Bot")
+		err := IssueCommentHandler(ctx, e, mClient)
+		assert.Equal(t, nil, err)
+	})
 	t.Run("Test unknown Command", func(t *testing.T) {
 		webhookPayload := getIssueCommentMockPayload("unknown-command")
 		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
@@ -706,3 +872,45 @@ func TestHandleGif(t *testing.T) {
 		assert.NotEqual(t, nil, err)
 	})
 }
+
+func TestResolveCommand(t *testing.T) {
+	cfg := &types.PaulConfig{
+		Labels: types.Labels{Prefixes: []string{"kind"}},
+		CustomCommands: map[string]types.CustomCommand{
+			"ship":        {Alias: "/merge"},
+			"docs":        {Response: "See the docs"},
+			"merge":       {Response: "not allowed"},
+			"unkind":      {Alias: "/remove-kind"},
+			"remove-kind": {Response: "not allowed"},
+		},
+	}
+	var resolveTests = []struct {
+		cmd      string
+		expected string
+		response string
+	}{
+		{cmd: "ship", expected: "merge"},
+		{cmd: "docs", expected: "docs", response: "See the docs"},
+		{cmd: "merge", expected: "merge"},
+		{cmd: "kind", expected: "kind"},
+		{cmd: "unkind", expected: "remove-kind"},
+		{cmd: "remove-kind", expected: "remove-kind"},
+		{cmd: "unknown", expected: "unknown"},
+	}
+	for _, resolveTest := range resolveTests {
+		t.Run(resolveTest.cmd, func(t *testing.T) {
+			cmd, response := resolveCommand(cfg, resolveTest.cmd)
+			assert.Equal(t, resolveTest.expected, cmd)
+			assert.Equal(t, resolveTest.response, response)
+		})
+	}
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	event := getHoldMockEvent(t)
+	assert.Equal(
+		t,
+		"Thanks @Spazzy757, see #9 in Spazzy757/paul",
+		fillPlaceholders("Thanks @{{sender}}, see #{{number}} in {{repo}}", event),
+	)
+}
@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const undefinedLabelsMessage = "These labels are not defined in PAUL.yaml: %v"
+
+// defaultLabels are the labels Paul's features depend on
+var defaultLabels = []*github.Label{
+	{
+		Name:        github.String(staleLabel),
+		Color:       github.String("cfd3d7"),
+		Description: github.String("Pull Request has not been updated in a while"),
+	},
+	{
+		Name:        github.String(mergeLabel),
+		Color:       github.String("0e8a16"),
+		Description: github.String("Paul will merge the Pull Request once it is mergeable"),
+	},
+	{
+		Name:        github.String(holdLabel),
+		Color:       github.String("e11d21"),
+		Description: github.String("Paul will not merge the Pull Request until /unhold"),
+	},
+	{
+		Name:        github.String(lgtmLabel),
+		Color:       github.String("15dd18"),
+		Description: github.String("A maintainer has approved the Pull Request with /lgtm"),
+	},
+}
+
+// requiredLabels returns the labels the enabled features add to Pull Requests
+func requiredLabels(cfg types.PaulConfig) []*github.Label {
+	var labels []*github.Label
+	for _, label := range defaultLabels {
+		switch {
+		case label.GetName() == staleLabel && cfg.PullRequests.StaleTime > 0,
+			label.GetName() == mergeLabel && cfg.PullRequests.AutomatedMerge:
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// labelByName returns one of the default labels
+func labelByName(name string) *github.Label {
+	for _, label := range defaultLabels {
+		if label.GetName() == name {
+			return label
+		}
+	}
+	return &github.Label{Name: github.String(name)}
+}
+
+// definitionLabel turns a label definition into a Github label
+func definitionLabel(definition types.LabelDefinition) *github.Label {
+	label := &github.Label{Name: github.String(definition.Name)}
+	if definition.Color != "" {
+		label.Color = github.String(strings.ToLower(strings.TrimPrefix(definition.Color, "#")))
+	}
+	if definition.Description != "" {
+		label.Description = github.String(definition.Description)
+	}
+	return label
+}
+
+// syncLabels creates the defined labels and updates them when their color or
+// description differs, required labels are only created when missing
+func syncLabels(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	definitions []types.LabelDefinition,
+	required []*github.Label,
+) error {
+	if len(definitions) == 0 && len(required) == 0 {
+		return nil
+	}
+	existing, err := listLabels(ctx, client, owner, repo)
+	if err != nil {
+		return err
+	}
+	for _, definition := range definitions {
+		label := definitionLabel(definition)
+		current, ok := existing[strings.ToLower(definition.Name)]
+		switch {
+		case !ok:
+			_, _, err = client.Issues.CreateLabel(ctx, owner, repo, label)
+		case labelChanged(current, label):
+			_, _, err = client.Issues.EditLabel(ctx, owner, repo, url.PathEscape(current.GetName()), label)
+		}
+		if err != nil {
+			return err
+		}
+		existing[strings.ToLower(definition.Name)] = label
+	}
+	for _, label := range required {
+		if _, ok := existing[strings.ToLower(label.GetName())]; ok {
+			continue
+		}
+		if _, _, err = client.Issues.CreateLabel(ctx, owner, repo, label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelChanged checks if the color or description set on the wanted label
+// differs from the current label
+func labelChanged(current, wanted *github.Label) bool {
+	return (wanted.Color != nil && !strings.EqualFold(current.GetColor(), wanted.GetColor())) ||
+		(wanted.Description != nil && current.GetDescription() != wanted.GetDescription())
+}
+
+// listLabels returns all of a repository's labels keyed by lowercase name
+func listLabels(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+) (map[string]*github.Label, error) {
+	labels := map[string]*github.Label{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, res, err := client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range page {
+			labels[strings.ToLower(label.GetName())] = label
+		}
+		if res.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// resolveLabels maps names and aliases to the defined labels, labels that are
+// not defined are returned separately, any label is allowed when none are defined
+func resolveLabels(cfg types.Labels, names []string) ([]string, []string) {
+	if len(cfg.Definitions) == 0 {
+		return names, nil
+	}
+	var resolved, undefined []string
+	for _, name := range names {
+		definition, ok := cfg.Lookup(name)
+		if !ok {
+			undefined = append(undefined, name)
+			continue
+		}
+		resolved = append(resolved, definition.Name)
+	}
+	return resolved, undefined
+}
+
+// parseLabels splits command arguments into labels, labels are separated by
+// commas and can be quoted i.e `bug, area/api, "good first issue"`,
+// without commas or quotes all the arguments are a single label
+func parseLabels(args []string) []string {
+	text := strings.TrimSpace(strings.Join(args, " "))
+	if !strings.ContainsAny(text, `,"`) {
+		if text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+	// quoted labels can be separated by spaces when there are no commas
+	splitOnSpace := !strings.Contains(text, ",")
+	var labels []string
+	var current strings.Builder
+	quoted := false
+	flush := func() {
+		if label := strings.TrimSpace(current.String()); label != "" {
+			labels = append(labels, label)
+		}
+		current.Reset()
+	}
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ',' || (splitOnSpace && r == ' ')):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return labels
+}
+
+// prefixLabels adds the prefix to each label i.e "kind" and "bug" is "kind/bug"
+func prefixLabels(prefix string, labels []string) []string {
+	prefixed := make([]string, len(labels))
+	for i, label := range labels {
+		prefixed[i] = prefix + "/" + label
+	}
+	return prefixed
+}
+
+// isLabelPattern checks if a label should be matched against existing labels
+func isLabelPattern(label string) bool {
+	return strings.ContainsAny(label, "*?[")
+}
+
+// matchLabels returns the labels matching the pattern, ignoring case
+func matchLabels(pattern string, labels []*github.Label) []string {
+	var matched []string
+	for _, label := range labels {
+		ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(label.GetName()))
+		if err == nil && ok {
+			matched = append(matched, label.GetName())
+		}
+	}
+	return matched
+}
@@ -0,0 +1,211 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncLabels(t *testing.T) {
+	t.Run("Test Labels Are Created And Updated", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `[
+				  {"name":"Bug","color":"ffffff","description":"old"},
+				  {"name":"stale","color":"000000"},
+				  {"name":"docs","color":"0075ca"}
+				]`)
+				return
+			}
+			assert.Equal(t, http.MethodPost, r.Method)
+			v := new(github.Label)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, &github.Label{
+				Name:        github.String("enhancement"),
+				Color:       github.String("a2eeef"),
+				Description: github.String("New feature"),
+			}, v)
+			fmt.Fprint(w, `{}`)
+		})
+		edited := false
+		mux.HandleFunc("/repos/Spazzy757/paul/labels/Bug", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			v := new(github.Label)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "d73a4a", v.GetColor())
+			assert.Equal(t, "Something isn't working", v.GetDescription())
+			edited = true
+			fmt.Fprint(w, `{}`)
+		})
+		definitions := []types.LabelDefinition{
+			{Name: "bug", Color: "#D73A4A", Description: "Something isn't working"},
+			{Name: "enhancement", Color: "a2eeef", Description: "New feature"},
+			{Name: "docs"},
+		}
+		err := syncLabels(
+			context.Background(),
+			mClient,
+			"Spazzy757",
+			"paul",
+			definitions,
+			defaultLabels[:1],
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, edited)
+	})
+	t.Run("Test Labels With Slashes Are Escaped", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"name":"kind/bug","color":"ffffff"}]`)
+		})
+		edited := false
+		mux.HandleFunc("/repos/Spazzy757/paul/labels/kind/bug", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/repos/Spazzy757/paul/labels/kind%2Fbug", r.URL.EscapedPath())
+			edited = true
+			fmt.Fprint(w, `{}`)
+		})
+		definitions := []types.LabelDefinition{{Name: "kind/bug", Color: "d73a4a"}}
+		err := syncLabels(context.Background(), mClient, "Spazzy757", "paul", definitions, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, edited)
+	})
+	t.Run("Test Nothing Is Requested Without Labels", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := syncLabels(context.Background(), mClient, "Spazzy757", "paul", nil, nil)
+		assert.Equal(t, nil, err)
+	})
+	t.Run("Test List Failure Returns Error", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/labels", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		err := syncLabels(context.Background(), mClient, "Spazzy757", "paul", nil, defaultLabels)
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func TestRequiredLabels(t *testing.T) {
+	cfg := types.PaulConfig{}
+	assert.Empty(t, requiredLabels(cfg))
+	cfg.PullRequests.StaleTime = 10
+	assert.Equal(t, []*github.Label{defaultLabels[0]}, requiredLabels(cfg))
+	cfg.PullRequests.AutomatedMerge = true
+	assert.Equal(t, defaultLabels[:2], requiredLabels(cfg))
+}
+
+func TestResolveLabels(t *testing.T) {
+	labels := types.Labels{
+		Definitions: []types.LabelDefinition{
+			{Name: "bug", Aliases: []string{"defect"}},
+			{Name: "good first issue"},
+		},
+	}
+	var resolveTests = []struct {
+		name              string
+		labels            types.Labels
+		names             []string
+		expectedResolved  []string
+		expectedUndefined []string
+	}{
+		{
+			name:             "No Definitions Allows Any Label",
+			names:            []string{"anything"},
+			expectedResolved: []string{"anything"},
+		},
+		{
+			name:             "Aliases And Case Are Resolved",
+			labels:           labels,
+			names:            []string{"Defect", "good first issue"},
+			expectedResolved: []string{"bug", "good first issue"},
+		},
+		{
+			name:              "Undefined Labels Are Refused",
+			labels:            labels,
+			names:             []string{"bug", "wontfix"},
+			expectedResolved:  []string{"bug"},
+			expectedUndefined: []string{"wontfix"},
+		},
+	}
+	for _, test := range resolveTests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, undefined := resolveLabels(test.labels, test.names)
+			assert.Equal(t, test.expectedResolved, resolved)
+			assert.Equal(t, test.expectedUndefined, undefined)
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	var parseTests = []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "Single Label",
+			args:     []string{"bug"},
+			expected: []string{"bug"},
+		},
+		{
+			name:     "Label With Spaces",
+			args:     []string{"good", "first", "issue"},
+			expected: []string{"good first issue"},
+		},
+		{
+			name:     "Comma Separated",
+			args:     []string{"bug,", "area/api,", `"good`, "first", `issue"`},
+			expected: []string{"bug", "area/api", "good first issue"},
+		},
+		{
+			name:     "Quoted Labels",
+			args:     []string{`"good`, "first", `issue"`, "bug"},
+			expected: []string{"good first issue", "bug"},
+		},
+		{
+			name:     "Comma In Quotes",
+			args:     []string{`"a,`, `b",`, "c"},
+			expected: []string{"a, b", "c"},
+		},
+		{
+			name:     "Empty",
+			args:     []string{},
+			expected: nil,
+		},
+	}
+	for _, test := range parseTests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseLabels(test.args))
+		})
+	}
+}
+
+func TestPrefixLabels(t *testing.T) {
+	assert.Equal(t, []string{"kind/bug", "kind/cleanup"}, prefixLabels("kind", []string{"bug", "cleanup"}))
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := []*github.Label{
+		{Name: github.String("area/api")},
+		{Name: github.String("Area/Docs")},
+		{Name: github.String("kind/bug")},
+	}
+	assert.Equal(t, []string{"area/api", "Area/Docs"}, matchLabels("area/*", labels))
+	assert.Empty(t, matchLabels("size/*", labels))
+	assert.Equal(t, true, isLabelPattern("area/*"))
+	assert.Equal(t, false, isLabelPattern("area/api"))
+}
@@ -10,7 +10,7 @@ import (
 )
 
 const (
-	emptyDescriptionMessage = "There seems to be no description in your Pull Request.Please add an understanding of what this change proposes to do and why it is needed"
+	emptyDescriptionMessage = types.DefaultEmptyDescriptionMessage
 )
 
 // PullRequestHandler handler for the pull request event
@@ -18,6 +18,22 @@ func PullRequestHandler(
 	ctx context.Context,
 	event *github.PullRequestEvent,
 	client *github.Client,
+) error {
+	// The config is validated first so that a config which fails to load,
+	// or a check that fails, doesn't stop the config being reported on
+	validationErr := configValidationCheck(ctx, client, event)
+	err := pullRequestChecks(ctx, event, client)
+	if err != nil {
+		return err
+	}
+	return validationErr
+}
+
+// pullRequestChecks runs the checks configured in the repositories config
+func pullRequestChecks(
+	ctx context.Context,
+	event *github.PullRequestEvent,
+	client *github.Client,
 ) error {
 	// Get Paul Config
 	cfg, configErr := config.GetPaulConfig(
@@ -31,6 +47,10 @@ func PullRequestHandler(
 		return configErr
 	}
 	var err error
+	err = cherryPickCheck(ctx, client, event)
+	if err != nil {
+		return err
+	}
 	err = branchDestroyerCheck(ctx, cfg, client, event)
 	if err != nil {
 		return err
@@ -52,6 +72,14 @@ func PullRequestHandler(
 		return err
 	}
 	err = dcoCheck(ctx, cfg, client, event)
+	if err != nil {
+		return err
+	}
+	err = holdCheck(ctx, client, event)
+	if err != nil {
+		return err
+	}
+	err = dismissApprovals(ctx, cfg, client, event)
 	return err
 }
 
@@ -96,7 +124,21 @@ func emptyDescriptionCheck(
 			client,
 			message,
 		)
-		if cfg.EmptyDescriptionCheck.Enforced {
+		if err != nil {
+			return err
+		}
+		// a template gives the author something to fill in instead of closing
+		switch {
+		case cfg.EmptyDescriptionCheck.Template != "":
+			err = editDescription(
+				ctx,
+				client,
+				event.PullRequest.Base.Repo.Owner.GetLogin(),
+				event.PullRequest.Base.Repo.GetName(),
+				event.PullRequest.GetNumber(),
+				cfg.EmptyDescriptionCheck.Template,
+			)
+		case cfg.EmptyDescriptionCheck.Enforced:
 			err = closePullRequest(ctx, client, event)
 		}
 		return err
@@ -113,11 +155,8 @@ func branchDestroyerCheck(
 ) error {
 	if cfg.BranchDestroyer.Enabled &&
 		event.GetAction() == "closed" &&
-		event.PullRequest.Head.GetRef() != event.Repo.GetDefaultBranch() &&
 		event.PullRequest.GetMerged() &&
-		!checkStringInList(
-			cfg.BranchDestroyer.ProtectedBranches,
-			event.PullRequest.Head.GetRef()) {
+		!isProtectedBranch(cfg, event.Repo, event.PullRequest.Head.GetRef()) {
 		err := branchDestroyer(
 			ctx,
 			event.GetPullRequest(),
@@ -129,6 +168,13 @@ func branchDestroyerCheck(
 	return nil
 }
 
+// isProtectedBranch checks if the branch is the default branch or one of the
+// protected branches, Paul never deletes or rewrites these
+func isProtectedBranch(cfg types.PaulConfig, repo *github.Repository, branch string) bool {
+	return branch == repo.GetDefaultBranch() ||
+		checkStringInList(cfg.BranchDestroyer.ProtectedBranches, branch)
+}
+
 // limitPRCheck
 func limitPRCheck(
 	ctx context.Context,
@@ -210,6 +256,82 @@ func verifiedCommitCheck(
 	return nil
 }
 
+// configValidationCheck validates any config changed in a Pull Request
+// this runs regardless of the current config so a broken config
+// can be caught before it is merged
+func configValidationCheck(
+	ctx context.Context,
+	client *github.Client,
+	event *github.PullRequestEvent,
+) error {
+	switch event.GetAction() {
+	case "opened", "reopened", "synchronize":
+	default:
+		return nil
+	}
+	pr := event.GetPullRequest()
+	owner := pr.Base.Repo.Owner.GetLogin()
+	repo := pr.Base.Repo.GetName()
+	files, err := listPullRequestFiles(ctx, client, owner, repo, pr.GetNumber())
+	if err != nil {
+		return err
+	}
+	problemsByFile := map[string][]config.Problem{}
+	for _, file := range files {
+		if !config.IsConfigFile(file.GetFilename()) || file.GetStatus() == "removed" {
+			continue
+		}
+		// Commits from forks can be read through the base repository
+		content, _, _, err := client.Repositories.GetContents(
+			ctx,
+			owner,
+			repo,
+			file.GetFilename(),
+			&github.RepositoryContentGetOptions{Ref: pr.Head.GetSHA()},
+		)
+		if err != nil {
+			return err
+		}
+		raw, err := content.GetContent()
+		if err != nil {
+			return err
+		}
+		problemsByFile[file.GetFilename()] = config.Validate([]byte(raw))
+	}
+	if len(problemsByFile) == 0 {
+		return nil
+	}
+	_, _, err = client.Checks.CreateCheckRun(
+		ctx,
+		owner,
+		repo,
+		createConfigCheck(event, problemsByFile),
+	)
+	return err
+}
+
+// listPullRequestFiles returns every file changed by a Pull Request
+func listPullRequestFiles(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	number int,
+) ([]*github.CommitFile, error) {
+	var files []*github.CommitFile
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, res, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page...)
+		if res.NextPage == 0 {
+			return files, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
 // reviewComment sends a review comment to a Pull Request
 func reviewComment(
 	ctx context.Context,
@@ -217,11 +339,16 @@ func reviewComment(
 	client *github.Client,
 	message string,
 ) error {
+	// A redelivered webhook should not leave the same comment twice
+	reviewed, err := hasBotReview(ctx, pr, client, message)
+	if err != nil || reviewed {
+		return err
+	}
 	pullRequestReviewRequest := &github.PullRequestReviewRequest{
 		Body:  &message,
 		Event: github.String("COMMENT"),
 	}
-	_, _, err := client.PullRequests.CreateReview(
+	_, _, err = client.PullRequests.CreateReview(
 		ctx,
 		*pr.Base.User.Login,
 		pr.Base.Repo.GetName(),
@@ -231,6 +358,37 @@ func reviewComment(
 	return err
 }
 
+// hasBotReview checks if a bot already left a review with the given message
+func hasBotReview(
+	ctx context.Context,
+	pr *github.PullRequest,
+	client *github.Client,
+	message string,
+) (bool, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, res, err := client.PullRequests.ListReviews(
+			ctx,
+			pr.Base.User.GetLogin(),
+			pr.Base.Repo.GetName(),
+			pr.GetNumber(),
+			opts,
+		)
+		if err != nil {
+			return false, err
+		}
+		for _, review := range reviews {
+			if review.User.GetType() == "Bot" && review.GetBody() == message {
+				return true, nil
+			}
+		}
+		if res.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
 // branchDestroyer will delete a branch
 func branchDestroyer(
 	ctx context.Context,
@@ -3,6 +3,7 @@ package github
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/Spazzy757/paul/pkg/types"
 	"github.com/google/go-github/v49/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func getMockPayload() []byte {
@@ -33,6 +35,10 @@ func TestCreateReview(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -44,6 +50,27 @@ func TestCreateReview(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
 		req.Header.Set("X-GitHub-Event", "pull_request")
 
+		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+		e := event.(*github.PullRequestEvent)
+		err := reviewComment(context.Background(), e.PullRequest, mClient, "test")
+		assert.Equal(t, nil, err)
+	})
+	t.Run("Test Existing Review Is Not Repeated", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+
+		webhookPayload := getMockPayload()
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1/reviews",
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				fmt.Fprint(w, `[{"id":1,"body":"test","user":{"login":"paul[bot]","type":"Bot"}}]`)
+			},
+		)
+
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+
 		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
 		e := event.(*github.PullRequestEvent)
 		err := reviewComment(context.Background(), e.PullRequest, mClient, "test")
@@ -82,6 +109,10 @@ func TestFirstPRCheck(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -207,6 +238,15 @@ func TestPullRequestHandler(t *testing.T) {
 			fmt.Fprint(w, `[{"number":1}]`)
 		},
 	)
+	for _, number := range []int{1, 2} {
+		mux.HandleFunc(
+			fmt.Sprintf("/repos/Spazzy757/paul/pulls/%d/files", number),
+			func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, r.Method, "GET")
+				fmt.Fprint(w, `[{"filename":"README.md","status":"modified"}]`)
+			},
+		)
+	}
 	yamlFile, err := os.ReadFile("../../.github/PAUL.yaml")
 	assert.Equal(t, nil, err)
 	mux.HandleFunc(
@@ -273,6 +313,10 @@ func TestPullRequestHandler(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -316,6 +360,10 @@ func TestPullRequestHandler(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/2/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -458,6 +506,10 @@ func TestLimitPRCheck(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				assert.Equal(t, r.Method, "POST")
 				fmt.Fprint(w, `{"id":1}`)
 			},
@@ -589,6 +641,10 @@ func TestEmptyDescriptionCheck(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -624,6 +680,10 @@ func TestEmptyDescriptionCheck(t *testing.T) {
 		mux.HandleFunc(
 			"/repos/Spazzy757/paul/pulls/1/reviews",
 			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
 				v := new(github.PullRequestReviewRequest)
 				_ = json.NewDecoder(r.Body).Decode(v)
 				assert.Equal(t, r.Method, "POST")
@@ -648,6 +708,56 @@ func TestEmptyDescriptionCheck(t *testing.T) {
 		err := emptyDescriptionCheck(context.Background(), cfg, mClient, e)
 		assert.Equal(t, nil, err)
 	})
+	t.Run("Test Empty Description Gets Template", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		cfg := types.PaulConfig{
+			EmptyDescriptionCheck: types.EmptyDescriptionCheck{
+				Enabled:  true,
+				Enforced: true,
+				Template: "## What\n\n## Why\n",
+			},
+		}
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1/reviews",
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					fmt.Fprint(w, `[]`)
+					return
+				}
+				fmt.Fprint(w, `{"id":1}`)
+			},
+		)
+		edited := false
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/issues/1",
+			func(w http.ResponseWriter, r *http.Request) {
+				v := new(github.IssueRequest)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assert.Equal(t, "PATCH", r.Method)
+				assert.Equal(t, &github.IssueRequest{Body: github.String("## What\n\n## Why\n")}, v)
+				edited = true
+				fmt.Fprint(w, `{}`)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1",
+			func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("Pull Request should not be closed")
+			},
+		)
+
+		webhookPayload := test.GetMockPayload("empty-description-pr")
+
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+
+		event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+		e := event.(*github.PullRequestEvent)
+		err := emptyDescriptionCheck(context.Background(), cfg, mClient, e)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, edited)
+	})
 
 	t.Run("Test Non Empty Description Does Nothing", func(t *testing.T) {
 		mClient, _, _, teardown := test.GetMockClient()
@@ -981,3 +1091,125 @@ func TestVerifyCheck(t *testing.T) {
 		assert.Equal(t, nil, err)
 	})
 }
+
+func TestConfigValidationCheck(t *testing.T) {
+	webhookPayload := getIssueCommentMockPayload("opened-pr")
+	req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	event, _ := github.ParseWebHook(github.WebHookType(req), webhookPayload)
+	e := event.(*github.PullRequestEvent)
+	content := base64.StdEncoding.EncodeToString([]byte("pull_requests:\n  stale_tim: 5\n  stale_time: -1\n"))
+
+	t.Run("Test Invalid Config Fails Check", func(t *testing.T) {
+		assertions := require.New(t)
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1/files",
+			func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("page") != "2" {
+					w.Header().Set(
+						"Link",
+						`<`+serverURL+baseURLPath+`/repos/Spazzy757/paul/pulls/1/files?page=2>; rel="next"`,
+					)
+					fmt.Fprint(w, `[{"filename":"README.md","status":"modified"}]`)
+					return
+				}
+				fmt.Fprint(w, `[{"filename":".github/PAUL.yaml","status":"modified"}]`)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/contents/.github/PAUL.yaml",
+			func(w http.ResponseWriter, r *http.Request) {
+				assertions.Equal(e.PullRequest.Head.GetSHA(), r.URL.Query().Get("ref"))
+				fmt.Fprintf(w, `{"type":"file","encoding":"base64","content":"%s"}`, content)
+			},
+		)
+		created := false
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/check-runs",
+			func(w http.ResponseWriter, r *http.Request) {
+				assertions.Equal("POST", r.Method)
+				v := new(github.CreateCheckRunOptions)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assertions.Equal(configValidation, v.Name)
+				assertions.Equal(failure, v.GetConclusion())
+				assertions.Len(v.Output.Annotations, 2)
+				assertions.Equal(2, v.Output.Annotations[0].GetStartLine())
+				assertions.Equal(3, v.Output.Annotations[1].GetStartLine())
+				created = true
+				fmt.Fprint(w, `{"id":1}`)
+			},
+		)
+		err := configValidationCheck(context.Background(), mClient, e)
+		assertions.NoError(err)
+		assertions.True(created)
+	})
+	t.Run("Test Config That Fails To Load Is Still Checked", func(t *testing.T) {
+		assertions := require.New(t)
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		broken := "pull_requests: [\n"
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1/files",
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"filename":".github/PAUL.yaml","status":"modified"}]`)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/contents/.github/PAUL.yaml",
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(
+					w,
+					`{"type":"file","encoding":"base64","content":"%s"}`,
+					base64.StdEncoding.EncodeToString([]byte(broken)),
+				)
+			},
+		)
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/contents/.github",
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{
+				    "type": "file",
+				    "name": "PAUL.yaml",
+				    "download_url": "`+serverURL+baseURLPath+`/download/PAUL.yaml"
+				}]`)
+			},
+		)
+		mux.HandleFunc("/download/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, broken)
+		})
+		created := false
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/check-runs",
+			func(w http.ResponseWriter, r *http.Request) {
+				v := new(github.CreateCheckRunOptions)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assertions.Equal(failure, v.GetConclusion())
+				created = true
+				fmt.Fprint(w, `{"id":1}`)
+			},
+		)
+		err := PullRequestHandler(context.Background(), e, mClient)
+		assertions.Error(err)
+		assertions.True(created)
+	})
+	t.Run("Test No Config Changes Does Nothing", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc(
+			"/repos/Spazzy757/paul/pulls/1/files",
+			func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"filename":".github/PAUL.yaml","status":"removed"}]`)
+			},
+		)
+		err := configValidationCheck(context.Background(), mClient, e)
+		assert.NoError(t, err)
+	})
+	t.Run("Test Error Listing Files", func(t *testing.T) {
+		mClient, _, _, teardown := test.GetMockClient()
+		defer teardown()
+		err := configValidationCheck(context.Background(), mClient, e)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Spazzy757/paul/pkg/config"
+	"github.com/google/go-github/v49/github"
+)
+
+// PushHandler handler for the push event
+func PushHandler(
+	ctx context.Context,
+	event *github.PushEvent,
+	client *github.Client,
+) error {
+	repo := event.GetRepo()
+	defaultBranchRef := fmt.Sprintf("refs/heads/%v", repo.GetDefaultBranch())
+	if event.GetRef() != defaultBranchRef || !configChanged(event) {
+		return nil
+	}
+	config.InvalidateCache(repo.Owner.GetLogin(), repo.GetName())
+	// Sync the labels straight away so they can be used with /label
+	cfg, err := config.GetPaulConfig(
+		ctx,
+		repo.Owner.GetLogin(),
+		repo.GetName(),
+		repo.GetDefaultBranch(),
+		client,
+	)
+	if err != nil {
+		return err
+	}
+	return syncLabels(
+		ctx,
+		client,
+		repo.Owner.GetLogin(),
+		repo.GetName(),
+		cfg.Labels.Definitions,
+		requiredLabels(cfg),
+	)
+}
+
+// configChanged checks if any commit in the push touched the config
+func configChanged(event *github.PushEvent) bool {
+	commits := append([]*github.HeadCommit{event.GetHeadCommit()}, event.Commits...)
+	for _, commit := range commits {
+		if commit == nil {
+			continue
+		}
+		for _, files := range [][]string{commit.Added, commit.Modified, commit.Removed} {
+			for _, file := range files {
+				if config.IsConfigFile(file) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushHandler(t *testing.T) {
+	t.Run("Test Push Webhook is Handled correctly", func(t *testing.T) {
+		mClient, _, _, teardown := test.GetMockClient()
+		defer teardown()
+		webhookPayload := getIssueCommentMockPayload("push-config")
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "push")
+		err := IncomingWebhook(context.Background(), req, webhookPayload, mClient)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestConfigChanged(t *testing.T) {
+	var pushTests = []struct {
+		name     string
+		commits  []*github.HeadCommit
+		expected bool
+	}{
+		{
+			name:     "No Commits",
+			expected: false,
+		},
+		{
+			name: "Config Modified",
+			commits: []*github.HeadCommit{
+				{Modified: []string{"README.md"}},
+				{Modified: []string{".github/PAUL.yaml"}},
+			},
+			expected: true,
+		},
+		{
+			name: "Root Config Added",
+			commits: []*github.HeadCommit{
+				{Added: []string{"PAUL.yaml"}},
+			},
+			expected: true,
+		},
+		{
+			name: "Config Removed",
+			commits: []*github.HeadCommit{
+				{Removed: []string{".github/PAUL.yaml"}},
+			},
+			expected: true,
+		},
+		{
+			name: "Other Files Changed",
+			commits: []*github.HeadCommit{
+				{Modified: []string{"docs/PAUL.yaml", "main.go"}},
+			},
+			expected: false,
+		},
+	}
+	for _, test := range pushTests {
+		t.Run(test.name, func(t *testing.T) {
+			event := &github.PushEvent{Commits: test.commits}
+			assert.Equal(t, test.expected, configChanged(event))
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	actionsApp             = "github-actions"
+	retestMessage          = "Re-running failed checks: %v"
+	nothingToRetestMessage = "There are no failed checks to re-run"
+)
+
+// paulChecks are the checks Paul creates, re-running them has no effect
+var paulChecks = map[string]bool{
+	dco:              true,
+	verified:         true,
+	configValidation: true,
+	hold:             true,
+}
+
+// retestHandler handles the /retest command, failed Github Actions runs
+// have their failed jobs re-run and other check suites are re-requested
+func retestHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+) error {
+	if !event.Issue.IsPullRequest() ||
+		!cfg.PullRequests.Retest.Enabled ||
+		!isAuthorOrMaintainer(cfg, event) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, event.Issue.GetNumber())
+	if err != nil {
+		return err
+	}
+	checkRuns, err := listFailedCheckRuns(ctx, client, owner, repo, pr.Head.GetSHA(), cfg.PullRequests.Retest.Checks)
+	if err != nil {
+		return err
+	}
+	if len(checkRuns) == 0 {
+		return createIssueComment(ctx, event, client, nothingToRetestMessage)
+	}
+	// several check runs can belong to the same suite
+	actionsSuites := map[int64]bool{}
+	otherSuites := map[int64]bool{}
+	var names []string
+	for _, checkRun := range checkRuns {
+		names = append(names, checkRun.GetName())
+		if checkRun.App.GetSlug() == actionsApp {
+			actionsSuites[checkRun.CheckSuite.GetID()] = true
+		} else {
+			otherSuites[checkRun.CheckSuite.GetID()] = true
+		}
+	}
+	if len(actionsSuites) > 0 {
+		err = rerunWorkflows(ctx, client, owner, repo, pr, actionsSuites)
+		if err != nil {
+			return err
+		}
+	}
+	for suiteID := range otherSuites {
+		_, err = client.Checks.ReRequestCheckSuite(ctx, owner, repo, suiteID)
+		if err != nil {
+			return err
+		}
+	}
+	sort.Strings(names)
+	message := fmt.Sprintf(retestMessage, strings.Join(names, ", "))
+	return createIssueComment(ctx, event, client, message)
+}
+
+// listFailedCheckRuns returns the failed check runs for a commit,
+// when allowed is not empty only those checks are returned
+func listFailedCheckRuns(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, sha string,
+	allowed []string,
+) ([]*github.CheckRun, error) {
+	var failedRuns []*github.CheckRun
+	opts := &github.ListCheckRunsOptions{
+		Status:      github.String(completed),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		results, res, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, checkRun := range results.CheckRuns {
+			if paulChecks[checkRun.GetName()] ||
+				!checkFailed(checkRun.GetConclusion()) ||
+				(len(allowed) > 0 && !checkStringInList(allowed, checkRun.GetName())) {
+				continue
+			}
+			failedRuns = append(failedRuns, checkRun)
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	return failedRuns, nil
+}
+
+// rerunWorkflows re-runs the failed jobs of the workflow runs
+// belonging to the check suites given
+func rerunWorkflows(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo string,
+	pr *github.PullRequest,
+	suites map[int64]bool,
+) error {
+	opts := &github.ListWorkflowRunsOptions{
+		Branch:      pr.Head.GetRef(),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		runs, res, err := client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs.WorkflowRuns {
+			if run.GetHeadSHA() != pr.Head.GetSHA() || !suites[run.GetCheckSuiteID()] {
+				continue
+			}
+			_, err = client.Actions.RerunFailedJobsByID(ctx, owner, repo, run.GetID())
+			if err != nil {
+				return err
+			}
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
+	return nil
+}
+
+// checkFailed checks if a check run concluded without passing
+func checkFailed(conclusion string) bool {
+	switch conclusion {
+	case failure, "timed_out", "cancelled", failed:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleRetestCheckRuns(mux *http.ServeMux) {
+	mux.HandleFunc("/repos/Spazzy757/paul/commits/deadbeef/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 4, "check_runs": [
+		  {"name": "build", "conclusion": "failure", "app": {"slug": "github-actions"}, "check_suite": {"id": 1}},
+		  {"name": "ci/circle", "conclusion": "timed_out", "app": {"slug": "circleci"}, "check_suite": {"id": 2}},
+		  {"name": "lint", "conclusion": "success", "app": {"slug": "github-actions"}, "check_suite": {"id": 1}},
+		  {"name": "Hold", "conclusion": "failure", "app": {"slug": "paul"}, "check_suite": {"id": 3}}
+		]}`)
+	})
+}
+
+func TestRetestHandler(t *testing.T) {
+	cfg := &types.PaulConfig{
+		PullRequests: types.PullRequests{Retest: types.Retest{Enabled: true}},
+	}
+	t.Run("Test Failed Checks Are Re-run", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[]`)
+		handleRetestCheckRuns(mux)
+		mux.HandleFunc("/repos/Spazzy757/paul/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"total_count": 2, "workflow_runs": [
+			  {"id": 10, "head_sha": "deadbeef", "check_suite_id": 1},
+			  {"id": 11, "head_sha": "cafe", "check_suite_id": 1}
+			]}`)
+		})
+		rerun := false
+		mux.HandleFunc("/repos/Spazzy757/paul/actions/runs/10/rerun-failed-jobs", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			rerun = true
+			w.WriteHeader(http.StatusCreated)
+		})
+		rerequested := false
+		mux.HandleFunc("/repos/Spazzy757/paul/check-suites/2/rerequest", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			rerequested = true
+			w.WriteHeader(http.StatusCreated)
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(retestMessage, "build, ci/circle"))
+		err := retestHandler(context.Background(), cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, rerun)
+		assert.Equal(t, true, rerequested)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Only Allowed Checks Are Re-run", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleHoldPullRequest(mux, `[]`)
+		handleRetestCheckRuns(mux)
+		commented := handleExpectedComment(t, mux, nothingToRetestMessage)
+		allowCfg := &types.PaulConfig{
+			PullRequests: types.PullRequests{
+				Retest: types.Retest{Enabled: true, Checks: []string{"lint"}},
+			},
+		}
+		err := retestHandler(context.Background(), allowCfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Other Users Can Not Retest", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("someone")
+		err := retestHandler(context.Background(), cfg, event, mClient)
+		assert.Equal(t, nil, err)
+	})
+}
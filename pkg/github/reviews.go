@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+
+	"github.com/google/go-github/v49/github"
+)
+
+// PullRequestReviewHandler runs the commands in the body of a submitted
+// Pull Request review, edited reviews don't say what the body was so
+// they are ignored
+func PullRequestReviewHandler(
+	ctx context.Context,
+	event *github.PullRequestReviewEvent,
+	client *github.Client,
+) error {
+	if event.GetAction() != "submitted" || event.Review.GetBody() == "" {
+		return nil
+	}
+	comment := &github.IssueComment{
+		Body: event.Review.Body,
+		User: event.Review.User,
+	}
+	return IssueCommentHandler(
+		ctx,
+		pullRequestCommentEvent(
+			"created",
+			event.PullRequest,
+			comment,
+			nil,
+			event.Repo,
+			event.Sender,
+			event.Installation,
+		),
+		client,
+	)
+}
+
+// PullRequestReviewCommentHandler runs the commands in inline review comments
+func PullRequestReviewCommentHandler(
+	ctx context.Context,
+	event *github.PullRequestReviewCommentEvent,
+	client *github.Client,
+) error {
+	comment := &github.IssueComment{
+		ID:   event.Comment.ID,
+		Body: event.Comment.Body,
+		User: event.Comment.User,
+	}
+	return IssueCommentHandler(
+		ctx,
+		pullRequestCommentEvent(
+			event.GetAction(),
+			event.PullRequest,
+			comment,
+			event.Changes,
+			event.Repo,
+			event.Sender,
+			event.Installation,
+		),
+		client,
+	)
+}
+
+// pullRequestCommentEvent turns a review or review comment into an
+// IssueCommentEvent so it goes through the same commands
+func pullRequestCommentEvent(
+	action string,
+	pr *github.PullRequest,
+	comment *github.IssueComment,
+	changes *github.EditChange,
+	repo *github.Repository,
+	sender *github.User,
+	installation *github.Installation,
+) *github.IssueCommentEvent {
+	return &github.IssueCommentEvent{
+		Action: github.String(action),
+		Issue: &github.Issue{
+			Number: pr.Number,
+			Title:  pr.Title,
+			Body:   pr.Body,
+			State:  pr.State,
+			User:   pr.User,
+			Labels: pr.Labels,
+			// marks the issue as a Pull Request
+			PullRequestLinks: &github.PullRequestLinks{
+				URL:     pr.URL,
+				HTMLURL: pr.HTMLURL,
+			},
+		},
+		Comment:      comment,
+		Changes:      changes,
+		Repo:         repo,
+		Sender:       sender,
+		Installation: installation,
+	}
+}
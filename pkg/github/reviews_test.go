@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleRepositoryConfig(t *testing.T, mux *http.ServeMux, serverURL string) {
+	yamlFile, err := os.ReadFile("../../.github/PAUL.yaml")
+	assert.Equal(t, nil, err)
+	mux.HandleFunc("/repos/Spazzy757/paul/contents/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{
+		  "type": "file",
+		  "name": "PAUL.yaml",
+		  "download_url": "`+serverURL+baseURLPath+`/download/PAUL.yaml"
+		}]`)
+	})
+	mux.HandleFunc("/download/PAUL.yaml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, string(yamlFile))
+	})
+}
+
+func handleExpectedLabels(t *testing.T, mux *http.ServeMux, expected []string) *bool {
+	labeled := false
+	mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+		var v []string
+		_ = json.NewDecoder(r.Body).Decode(&v)
+		assert.Equal(t, expected, v)
+		labeled = true
+		fmt.Fprint(w, `[]`)
+	})
+	return &labeled
+}
+
+func getReviewMockPullRequest() *github.PullRequest {
+	return &github.PullRequest{
+		Number: github.Int(9),
+		State:  github.String(open),
+		User:   &github.User{Login: github.String("someone")},
+		URL:    github.String("https://api.github.com/repos/Spazzy757/paul/pulls/9"),
+	}
+}
+
+func getReviewMockRepository() *github.Repository {
+	return &github.Repository{
+		Name:          github.String("paul"),
+		FullName:      github.String("Spazzy757/paul"),
+		DefaultBranch: github.String("main"),
+		Owner:         &github.User{Login: github.String("Spazzy757")},
+	}
+}
+
+func TestPullRequestReviewHandler(t *testing.T) {
+	maintainer := &github.User{Login: github.String("Spazzy757")}
+	t.Run("Test Command In Review Body Is Run", func(t *testing.T) {
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleRepositoryConfig(t, mux, serverURL)
+		labeled := handleExpectedLabels(t, mux, []string{"bug"})
+		err := PullRequestReviewHandler(context.Background(), &github.PullRequestReviewEvent{
+			Action:      github.String("submitted"),
+			Review:      &github.PullRequestReview{Body: github.String("/label bug"), User: maintainer},
+			PullRequest: getReviewMockPullRequest(),
+			Repo:        getReviewMockRepository(),
+			Sender:      maintainer,
+		}, mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *labeled)
+	})
+	t.Run("Test Review Without Body Is Ignored", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := PullRequestReviewHandler(context.Background(), &github.PullRequestReviewEvent{
+			Action:      github.String("submitted"),
+			Review:      &github.PullRequestReview{State: github.String("APPROVED"), User: maintainer},
+			PullRequest: getReviewMockPullRequest(),
+			Repo:        getReviewMockRepository(),
+			Sender:      maintainer,
+		}, mClient)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestPullRequestReviewCommentHandler(t *testing.T) {
+	maintainer := &github.User{Login: github.String("Spazzy757")}
+	getEvent := func(action, body, previous string) *github.PullRequestReviewCommentEvent {
+		event := &github.PullRequestReviewCommentEvent{
+			Action:      github.String(action),
+			Comment:     &github.PullRequestComment{Body: github.String(body), User: maintainer},
+			PullRequest: getReviewMockPullRequest(),
+			Repo:        getReviewMockRepository(),
+			Sender:      maintainer,
+		}
+		if previous != "" {
+			event.Changes = &github.EditChange{Body: &github.EditBody{From: github.String(previous)}}
+		}
+		return event
+	}
+	t.Run("Test Command In Review Comment Is Run", func(t *testing.T) {
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleRepositoryConfig(t, mux, serverURL)
+		labeled := handleExpectedLabels(t, mux, []string{"bug"})
+		err := PullRequestReviewCommentHandler(context.Background(), getEvent("created", "/label bug", ""), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *labeled)
+	})
+	t.Run("Test Edited Command Is Run", func(t *testing.T) {
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleRepositoryConfig(t, mux, serverURL)
+		labeled := handleExpectedLabels(t, mux, []string{"bug"})
+		err := PullRequestReviewCommentHandler(
+			context.Background(),
+			getEvent("edited", "/label bug", "/label bgu"),
+			mClient,
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *labeled)
+	})
+	t.Run("Test Unchanged Command Is Not Run Again", func(t *testing.T) {
+		mClient, mux, serverURL, teardown := test.GetMockClient()
+		defer teardown()
+		handleRepositoryConfig(t, mux, serverURL)
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("label should not be added again")
+		})
+		err := PullRequestReviewCommentHandler(
+			context.Background(),
+			getEvent("edited", "/label bug\n\nfixed a typo", "/label bug\n\nfixed a tpyo"),
+			mClient,
+		)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestRunsCommand(t *testing.T) {
+	var runsCommandTests = []struct {
+		name     string
+		action   string
+		body     string
+		previous *string
+		expected bool
+	}{
+		{name: "Created", action: "created", body: "/merge", expected: true},
+		{name: "Deleted", action: "deleted", body: "/merge", expected: false},
+		{name: "Edited Without Changes", action: "edited", body: "/merge", expected: false},
+		{name: "Edited To A Command", action: "edited", body: "/merge", previous: github.String("merge"), expected: true},
+		{name: "Edited Args", action: "edited", body: "/label bug", previous: github.String("/label bgu"), expected: true},
+		{name: "Edited Text", action: "edited", body: "/merge\nthanks", previous: github.String("/merge\nthx"), expected: false},
+		{name: "Command Removed", action: "edited", body: "merge", previous: github.String("/merge"), expected: false},
+	}
+	for _, runsCommandTest := range runsCommandTests {
+		t.Run(runsCommandTest.name, func(t *testing.T) {
+			event := &github.IssueCommentEvent{
+				Action:  github.String(runsCommandTest.action),
+				Comment: &github.IssueComment{Body: github.String(runsCommandTest.body)},
+			}
+			if runsCommandTest.previous != nil {
+				event.Changes = &github.EditChange{Body: &github.EditBody{From: runsCommandTest.previous}}
+			}
+			assert.Equal(t, runsCommandTest.expected, runsCommand(event))
+		})
+	}
+}
@@ -32,12 +32,36 @@ func PullRequestsScheduledJobs(
 	if handleError(err) {
 		return
 	}
+	// Make sure the labels the jobs add exist
+	syncRepositoryLabels(ctx, client, scheduledJobsInformationList)
 	// Check if Pull Requests Should Be Marked as Stale
 	markPullRequestsStale(ctx, client, scheduledJobsInformationList)
 	// Merges Pull Requests that are viable
 	mergePendingPullRequests(ctx, client, scheduledJobsInformationList)
 }
 
+func syncRepositoryLabels(
+	ctx context.Context,
+	client *github.Client,
+	informationList []*ScehduledJobInformation,
+) {
+	for _, scheduledJobsInformation := range informationList {
+		cfg := scheduledJobsInformation.Cfg
+		repo := scheduledJobsInformation.Repo
+		err := syncLabels(
+			ctx,
+			client,
+			repo.Owner.GetLogin(),
+			repo.GetName(),
+			cfg.Labels.Definitions,
+			requiredLabels(cfg),
+		)
+		if handleError(err) {
+			continue
+		}
+	}
+}
+
 func markPullRequestsStale(
 	ctx context.Context,
 	client *github.Client,
@@ -66,21 +90,43 @@ func mergePendingPullRequests(
 	client *github.Client,
 	informationList []*ScehduledJobInformation,
 ) {
-	var labeledPullRequests []*github.PullRequest
 	for _, scheduledJobsInformation := range informationList {
 		cfg := scheduledJobsInformation.Cfg
-		if cfg.PullRequests.AutomatedMerge {
-			labeledPullRequests = append(
-				labeledPullRequests,
-				checkLabels(mergeLabel, scheduledJobsInformation.PullRequests)...,
-			)
-		}
-	}
-	for _, pullRequest := range labeledPullRequests {
-		err := mergePullRequest(ctx, client, pullRequest)
-		if handleError(err) {
+		if !cfg.PullRequests.AutomatedMerge {
 			continue
 		}
+		labeledPullRequests := checkLabels(mergeLabel, scheduledJobsInformation.PullRequests)
+		for _, pullRequest := range labeledPullRequests {
+			approvals, err := approvalsMessage(ctx, client, &cfg, pullRequest)
+			if handleError(err) {
+				continue
+			}
+			switch {
+			case isHeld(pullRequest.Labels):
+				err = createCommentOnce(
+					ctx,
+					client,
+					pullRequest.Base.Repo.Owner.GetLogin(),
+					pullRequest.Base.Repo.GetName(),
+					pullRequest.GetNumber(),
+					heldSkippedMessage,
+				)
+			case approvals != "":
+				err = createCommentOnce(
+					ctx,
+					client,
+					pullRequest.Base.Repo.Owner.GetLogin(),
+					pullRequest.Base.Repo.GetName(),
+					pullRequest.GetNumber(),
+					approvals,
+				)
+			default:
+				err = mergePullRequest(ctx, client, pullRequest)
+			}
+			if handleError(err) {
+				continue
+			}
+		}
 	}
 }
 
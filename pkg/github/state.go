@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	open                = "open"
+	closed              = "closed"
+	closedMessage       = "Closed by @%v"
+	reopenedMessage     = "Reopened by @%v"
+	lockedMessage       = "This conversation has been locked by @%v"
+	lockedReasonMessage = "This conversation has been locked by @%v as %v"
+	unlockedMessage     = "This conversation has been unlocked by @%v"
+	invalidLockReason   = "%q is not a lock reason, use one of: %v"
+)
+
+// lockReasons are the reasons Github accepts for locking a conversation
+var lockReasons = []string{"off-topic", "too heated", "resolved", "spam"}
+
+// stateHandler handles the /close and /reopen commands
+func stateHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	state string,
+) error {
+	if !isAuthorOrMaintainer(cfg, event) || event.Issue.GetState() == state {
+		return nil
+	}
+	_, _, err := client.Issues.Edit(
+		ctx,
+		event.Repo.Owner.GetLogin(),
+		event.Repo.GetName(),
+		event.Issue.GetNumber(),
+		&github.IssueRequest{State: github.String(state)},
+	)
+	if err != nil {
+		return err
+	}
+	sender := event.Sender.GetLogin()
+	message := fmt.Sprintf(closedMessage, sender)
+	if state == open {
+		message = fmt.Sprintf(reopenedMessage, sender)
+	}
+	return createIssueComment(ctx, event, client, message)
+}
+
+// lockHandler handles the /lock and /unlock commands,
+// the args given to /lock are the reason i.e /lock too heated
+func lockHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	locked bool,
+	args []string,
+) error {
+	sender := event.Sender.GetLogin()
+	if !checkStringInList(cfg.Maintainers, sender) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	number := event.Issue.GetNumber()
+	if !locked {
+		_, err := client.Issues.Unlock(ctx, owner, repo, number)
+		if err != nil {
+			return err
+		}
+		return createIssueComment(ctx, event, client, fmt.Sprintf(unlockedMessage, sender))
+	}
+	reason := strings.ToLower(strings.Join(args, " "))
+	if reason != "" && !checkStringInList(lockReasons, reason) {
+		message := fmt.Sprintf(invalidLockReason, reason, strings.Join(lockReasons, ", "))
+		return createIssueComment(ctx, event, client, message)
+	}
+	// comment before locking so the reason is the last thing in the thread
+	message := fmt.Sprintf(lockedMessage, sender)
+	if reason != "" {
+		message = fmt.Sprintf(lockedReasonMessage, sender, reason)
+	}
+	err := createIssueComment(ctx, event, client, message)
+	if err != nil {
+		return err
+	}
+	_, err = client.Issues.Lock(ctx, owner, repo, number, &github.LockIssueOptions{LockReason: reason})
+	return err
+}
@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"alice"}}
+	var stateTests = []struct {
+		name    string
+		sender  string
+		state   string
+		message string
+	}{
+		{name: "Test Author Can Close", sender: "Spazzy757", state: closed, message: "Closed by @Spazzy757"},
+		{name: "Test Maintainer Can Close", sender: "alice", state: closed, message: "Closed by @alice"},
+	}
+	for _, stateTest := range stateTests {
+		t.Run(stateTest.name, func(t *testing.T) {
+			mClient, mux, _, teardown := test.GetMockClient()
+			defer teardown()
+			edited := false
+			mux.HandleFunc("/repos/Spazzy757/paul/issues/9", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				v := new(github.IssueRequest)
+				_ = json.NewDecoder(r.Body).Decode(v)
+				assert.Equal(t, stateTest.state, v.GetState())
+				edited = true
+				fmt.Fprint(w, `{}`)
+			})
+			commented := handleExpectedComment(t, mux, stateTest.message)
+			event := getHoldMockEvent(t)
+			event.Sender.Login = github.String(stateTest.sender)
+			err := stateHandler(context.Background(), cfg, event, mClient, stateTest.state)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, edited)
+			assert.Equal(t, true, *commented)
+		})
+	}
+	t.Run("Test Others Can Not Close", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("someone")
+		err := stateHandler(context.Background(), cfg, event, mClient, closed)
+		assert.Equal(t, nil, err)
+	})
+	t.Run("Test Open Issue Is Not Reopened", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		event := getHoldMockEvent(t)
+		event.Issue.State = github.String(open)
+		err := stateHandler(context.Background(), cfg, event, mClient, open)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestLockHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"Spazzy757"}}
+	t.Run("Test Lock With Reason", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		locked := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/lock", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			v := new(github.LockIssueOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "too heated", v.LockReason)
+			locked = true
+			w.WriteHeader(http.StatusNoContent)
+		})
+		commented := handleExpectedComment(t, mux, "This conversation has been locked by @Spazzy757 as too heated")
+		err := lockHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, true, []string{"too", "heated"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, locked)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Invalid Lock Reason", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		commented := handleExpectedComment(
+			t,
+			mux,
+			`"boring" is not a lock reason, use one of: off-topic, too heated, resolved, spam`,
+		)
+		err := lockHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, true, []string{"boring"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Unlock", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/lock", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.WriteHeader(http.StatusNoContent)
+		})
+		commented := handleExpectedComment(t, mux, "This conversation has been unlocked by @Spazzy757")
+		err := lockHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, false, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Non Maintainers Can Not Lock", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := lockHandler(context.Background(), &types.PaulConfig{}, getHoldMockEvent(t), mClient, true, nil)
+		assert.Equal(t, nil, err)
+	})
+}
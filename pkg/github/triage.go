@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	clearArg                = "clear"
+	unknownMilestoneMessage = "There is no open milestone called %q"
+	unknownPriorityMessage  = "%q is not a priority, use one of: %v"
+)
+
+// milestoneHandler handles the /milestone command, the milestone is found
+// by its title and "/milestone clear" removes it
+func milestoneHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error {
+	title := strings.TrimSpace(strings.Join(args, " "))
+	if title == "" || !checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	number := event.Issue.GetNumber()
+	if title == clearArg {
+		_, _, err := client.Issues.RemoveMilestone(ctx, owner, repo, number)
+		return err
+	}
+	milestone, err := findMilestone(ctx, client, owner, repo, title)
+	if err != nil {
+		return err
+	}
+	if milestone == nil {
+		return createIssueComment(ctx, event, client, fmt.Sprintf(unknownMilestoneMessage, title))
+	}
+	_, _, err = client.Issues.Edit(ctx, owner, repo, number, &github.IssueRequest{
+		Milestone: milestone.Number,
+	})
+	return err
+}
+
+// findMilestone returns the open milestone with the title, ignoring case
+func findMilestone(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, title string,
+) (*github.Milestone, error) {
+	opts := &github.MilestoneListOptions{
+		State:       open,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		milestones, res, err := client.Issues.ListMilestones(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, milestone := range milestones {
+			if strings.EqualFold(milestone.GetTitle(), title) {
+				return milestone, nil
+			}
+		}
+		if res.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// priorityHandler handles the /priority command, the label for the priority
+// replaces any other priority label and "/priority clear" removes them
+func priorityHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+	args []string,
+) error {
+	level := strings.ToLower(strings.TrimSpace(strings.Join(args, " ")))
+	if !cfg.Labels.Enabled || len(cfg.Labels.Priorities) == 0 || level == "" ||
+		!checkStringInList(cfg.Maintainers, event.Sender.GetLogin()) {
+		return nil
+	}
+	// levels are matched ignoring case
+	priorities := map[string]string{}
+	for name, label := range cfg.Labels.Priorities {
+		priorities[strings.ToLower(name)] = label
+	}
+	label, ok := priorities[level]
+	if level != clearArg && !ok {
+		var levels []string
+		for priority := range priorities {
+			levels = append(levels, priority)
+		}
+		sort.Strings(levels)
+		message := fmt.Sprintf(unknownPriorityMessage, level, strings.Join(levels, ", "))
+		return createIssueComment(ctx, event, client, message)
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	number := event.Issue.GetNumber()
+	for _, priority := range priorities {
+		if priority == label || !hasLabel(event.Issue.Labels, priority) {
+			continue
+		}
+		res, err := client.Issues.RemoveLabelForIssue(ctx, owner, repo, number, url.PathEscape(priority))
+		if err != nil && (res == nil || res.StatusCode != http.StatusNotFound) {
+			return err
+		}
+	}
+	if level == clearArg || hasLabel(event.Issue.Labels, label) {
+		return nil
+	}
+	_, _, err := client.Issues.AddLabelsToIssue(ctx, owner, repo, number, []string{label})
+	return err
+}
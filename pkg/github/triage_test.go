@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilestoneHandler(t *testing.T) {
+	cfg := &types.PaulConfig{Maintainers: []string{"Spazzy757"}}
+	handleMilestones := func(mux *http.ServeMux) {
+		mux.HandleFunc("/repos/Spazzy757/paul/milestones", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, open, r.URL.Query().Get("state"))
+			fmt.Fprint(w, `[{"number": 1, "title": "v1.1"}, {"number": 2, "title": "v1.2"}]`)
+		})
+	}
+	t.Run("Test Milestone Is Set By Title", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleMilestones(mux)
+		edited := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			v := new(github.IssueRequest)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, 2, v.GetMilestone())
+			edited = true
+			fmt.Fprint(w, `{}`)
+		})
+		err := milestoneHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"V1.2"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, edited)
+	})
+	t.Run("Test Unknown Milestone", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleMilestones(mux)
+		commented := handleExpectedComment(t, mux, `There is no open milestone called "v2"`)
+		err := milestoneHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"v2"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Milestone Is Cleared", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		cleared := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9", func(w http.ResponseWriter, r *http.Request) {
+			v := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, map[string]interface{}{"milestone": nil}, v)
+			cleared = true
+			fmt.Fprint(w, `{}`)
+		})
+		err := milestoneHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"clear"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, cleared)
+	})
+	t.Run("Test Non Maintainers Can Not Set Milestone", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := milestoneHandler(context.Background(), &types.PaulConfig{}, getHoldMockEvent(t), mClient, []string{"v1.2"})
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestPriorityHandler(t *testing.T) {
+	cfg := &types.PaulConfig{
+		Maintainers: []string{"Spazzy757"},
+		Labels: types.Labels{Enabled: true, Priorities: map[string]string{
+			"High": "priority/high",
+			"low":  "priority/low",
+		}},
+	}
+	t.Run("Test Priority Replaces Other Priorities", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		removed := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels/priority/low", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "/repos/Spazzy757/paul/issues/9/labels/priority%2Flow", r.URL.EscapedPath())
+			removed = true
+			fmt.Fprint(w, `[]`)
+		})
+		labeled := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels", func(w http.ResponseWriter, r *http.Request) {
+			var v []string
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, []string{"priority/high"}, v)
+			labeled = true
+			fmt.Fprint(w, `[]`)
+		})
+		event := getHoldMockEvent(t)
+		event.Issue.Labels = []*github.Label{{Name: github.String("priority/low")}}
+		err := priorityHandler(context.Background(), cfg, event, mClient, []string{"High"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, removed)
+		assert.Equal(t, true, labeled)
+	})
+	t.Run("Test Unknown Priority", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		commented := handleExpectedComment(t, mux, `"urgent" is not a priority, use one of: high, low`)
+		err := priorityHandler(context.Background(), cfg, getHoldMockEvent(t), mClient, []string{"urgent"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Priority Is Cleared", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		removed := false
+		mux.HandleFunc("/repos/Spazzy757/paul/issues/9/labels/priority/high", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			assert.Equal(t, "/repos/Spazzy757/paul/issues/9/labels/priority%2Fhigh", r.URL.EscapedPath())
+			removed = true
+			fmt.Fprint(w, `[]`)
+		})
+		event := getHoldMockEvent(t)
+		event.Issue.Labels = []*github.Label{{Name: github.String("priority/high")}}
+		err := priorityHandler(context.Background(), cfg, event, mClient, []string{"clear"})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, removed)
+	})
+	t.Run("Test Labels Must Be Enabled", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		disabledCfg := *cfg
+		disabledCfg.Labels.Enabled = false
+		err := priorityHandler(context.Background(), &disabledCfg, getHoldMockEvent(t), mClient, []string{"high"})
+		assert.Equal(t, nil, err)
+	})
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+)
+
+const (
+	rebaseBranch              = "paul/rebase-%d"
+	updatingBranchMessage     = "Updating this branch with the latest changes from %v"
+	updateBranchFailedMessage = "Can not update this branch: %v"
+	rebasedMessage            = "Rebased onto %v"
+	rebaseForkMessage         = "Can not rebase a branch from a fork, use /update-branch instead"
+	rebaseProtectedMessage    = "Can not rebase %v as it is a protected branch"
+	rebaseChangedMessage      = "Can not rebase, new commits were pushed while rebasing"
+	rebaseConflictMessage     = "Can not rebase onto %v, commit %v does not apply cleanly and will need to be rebased manually"
+)
+
+// updateBranchHandler handles the /update-branch command,
+// merging the base branch into the Pull Request's branch
+func updateBranchHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+) error {
+	if !event.Issue.IsPullRequest() || !isAuthorOrMaintainer(cfg, event) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, event.Issue.GetNumber())
+	if err != nil {
+		return err
+	}
+	_, _, err = client.PullRequests.UpdateBranch(
+		ctx,
+		owner,
+		repo,
+		pr.GetNumber(),
+		&github.PullRequestBranchUpdateOptions{ExpectedHeadSHA: pr.Head.SHA},
+	)
+	// the branch is updated in the background
+	var accepted *github.AcceptedError
+	if err == nil || errors.As(err, &accepted) {
+		message := fmt.Sprintf(updatingBranchMessage, pr.Base.GetRef())
+		return createIssueComment(ctx, event, client, message)
+	}
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) &&
+		errorResponse.Response.StatusCode == http.StatusUnprocessableEntity {
+		message := fmt.Sprintf(updateBranchFailedMessage, errorResponse.Message)
+		return createIssueComment(ctx, event, client, message)
+	}
+	return err
+}
+
+// rebaseHandler handles the /rebase command, the Pull Request's commits are
+// applied on top of the base branch on a temporary branch which then
+// replaces the Pull Request's branch
+func rebaseHandler(
+	ctx context.Context,
+	cfg *types.PaulConfig,
+	event *github.IssueCommentEvent,
+	client *github.Client,
+) error {
+	if !cfg.PullRequests.AllowRebase ||
+		!event.Issue.IsPullRequest() ||
+		!isAuthorOrMaintainer(cfg, event) {
+		return nil
+	}
+	owner := event.Repo.Owner.GetLogin()
+	repo := event.Repo.GetName()
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, event.Issue.GetNumber())
+	if err != nil {
+		return err
+	}
+	// Paul can only push to branches in the repository it is installed on
+	if pr.Head.Repo.GetFullName() != pr.Base.Repo.GetFullName() {
+		return createIssueComment(ctx, event, client, rebaseForkMessage)
+	}
+	// rebasing force pushes the Pull Request's branch
+	if isProtectedBranch(*cfg, event.Repo, pr.Head.GetRef()) {
+		message := fmt.Sprintf(rebaseProtectedMessage, pr.Head.GetRef())
+		return createIssueComment(ctx, event, client, message)
+	}
+	message, err := rebase(ctx, client, pr)
+	if err != nil {
+		return err
+	}
+	return createIssueComment(ctx, event, client, message)
+}
+
+// rebase rebases a Pull Request returning the message to report back with
+func rebase(
+	ctx context.Context,
+	client *github.Client,
+	pr *github.PullRequest,
+) (string, error) {
+	owner := pr.Base.Repo.Owner.GetLogin()
+	repo := pr.Base.Repo.GetName()
+	target := pr.Base.GetRef()
+	baseRef, _, err := client.Git.GetRef(ctx, owner, repo, "heads/"+target)
+	if err != nil {
+		return "", err
+	}
+	commits, err := listPullRequestCommits(ctx, client, owner, repo, pr.GetNumber())
+	if err != nil {
+		return "", err
+	}
+	branch := fmt.Sprintf(rebaseBranch, pr.GetNumber())
+	_, res, err := client.Git.CreateRef(ctx, owner, repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: baseRef.Object.SHA},
+	})
+	// a rebase that failed part way leaves the branch behind
+	if res != nil && res.StatusCode == http.StatusUnprocessableEntity {
+		err = updateBranch(ctx, client, owner, repo, branch, baseRef.Object.GetSHA())
+	}
+	if err != nil {
+		return "", err
+	}
+	head := baseRef.Object.GetSHA()
+	for _, commit := range commits {
+		// merge commits only bring in changes from the base branch
+		if len(commit.Parents) != 1 {
+			continue
+		}
+		head, err = applyCommit(ctx, client, owner, repo, branch, head, commit, "")
+		if errors.Is(err, errCommitConflict) {
+			message := fmt.Sprintf(rebaseConflictMessage, target, commit.GetSHA())
+			return message, deleteBranch(ctx, client, owner, repo, branch)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	headRef, _, err := client.Git.GetRef(ctx, owner, repo, "heads/"+pr.Head.GetRef())
+	if err != nil {
+		return "", err
+	}
+	if headRef.Object.GetSHA() != pr.Head.GetSHA() {
+		return rebaseChangedMessage, deleteBranch(ctx, client, owner, repo, branch)
+	}
+	err = updateBranch(ctx, client, owner, repo, pr.Head.GetRef(), head)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(rebasedMessage, target), deleteBranch(ctx, client, owner, repo, branch)
+}
+
+// deleteBranch deletes a branch Paul created
+func deleteBranch(
+	ctx context.Context,
+	client *github.Client,
+	owner, repo, branch string,
+) error {
+	_, err := client.Git.DeleteRef(ctx, owner, repo, "heads/"+branch)
+	return err
+}
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/Spazzy757/paul/pkg/types"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func handleUpdatePullRequest(mux *http.ServeMux, headRepo string) {
+	handleUpdatePullRequestBranch(mux, headRepo, "feature")
+}
+
+func handleUpdatePullRequestBranch(mux *http.ServeMux, headRepo, headRef string) {
+	mux.HandleFunc("/repos/Spazzy757/paul/pulls/9", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{
+		  "number": 9,
+		  "head": {"ref": "%v", "sha": "head", "repo": {"full_name": "%v"}},
+		  "base": {"ref": "main", "repo": {"name": "paul", "full_name": "Spazzy757/paul", "owner": {"login": "Spazzy757"}}}
+		}`, headRef, headRepo)
+	})
+}
+
+func TestUpdateBranchHandler(t *testing.T) {
+	cfg := &types.PaulConfig{}
+	t.Run("Test Branch Is Updated", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleUpdatePullRequest(mux, "Spazzy757/paul")
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/update-branch", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPut, r.Method)
+			v := new(github.PullRequestBranchUpdateOptions)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "head", v.GetExpectedHeadSHA())
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"message": "Updating pull request branch."}`)
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(updatingBranchMessage, "main"))
+		err := updateBranchHandler(context.Background(), cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Failed Update Is Reported", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleUpdatePullRequest(mux, "Spazzy757/paul")
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/update-branch", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "merge conflict between base and head"}`)
+		})
+		commented := handleExpectedComment(
+			t,
+			mux,
+			fmt.Sprintf(updateBranchFailedMessage, "merge conflict between base and head"),
+		)
+		err := updateBranchHandler(context.Background(), cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Others Can Not Update Branch", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		event := getHoldMockEvent(t)
+		event.Sender.Login = github.String("someone")
+		err := updateBranchHandler(context.Background(), cfg, event, mClient)
+		assert.Equal(t, nil, err)
+	})
+}
+
+func TestRebaseHandler(t *testing.T) {
+	cfg := &types.PaulConfig{PullRequests: types.PullRequests{AllowRebase: true}}
+	t.Run("Test Branch Is Rebased", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleUpdatePullRequest(mux, "Spazzy757/paul")
+		mux.HandleFunc("/repos/Spazzy757/paul/git/ref/heads/main", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"object": {"sha": "base"}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/ref/heads/feature", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"object": {"sha": "head"}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/pulls/9/commits", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `[{"sha": "one", "parents": [{"sha": "old-base"}], "commit": {"message": "Add feature"}}]`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/refs", func(w http.ResponseWriter, r *http.Request) {
+			v := new(github.Reference)
+			_ = json.NewDecoder(r.Body).Decode(v)
+			assert.Equal(t, "refs/heads/paul/rebase-9", v.GetRef())
+			fmt.Fprint(w, `{}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/commits/base", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"sha": "base", "tree": {"sha": "base-tree"}}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/git/commits", func(w http.ResponseWriter, r *http.Request) {
+			v := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			if v["parents"].([]interface{})[0] == "old-base" {
+				fmt.Fprint(w, `{"sha": "sibling"}`)
+				return
+			}
+			assert.Equal(t, []interface{}{"base"}, v["parents"])
+			assert.Equal(t, "Add feature", v["message"])
+			fmt.Fprint(w, `{"sha": "rebased"}`)
+		})
+		mux.HandleFunc("/repos/Spazzy757/paul/merges", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"sha": "merge", "commit": {"tree": {"sha": "merged-tree"}}}`)
+		})
+		deleted := false
+		mux.HandleFunc("/repos/Spazzy757/paul/git/refs/heads/paul/rebase-9", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				deleted = true
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		})
+		updated := ""
+		mux.HandleFunc("/repos/Spazzy757/paul/git/refs/heads/feature", func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPatch, r.Method)
+			v := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&v)
+			assert.Equal(t, true, v["force"])
+			updated = v["sha"].(string)
+			fmt.Fprint(w, `{}`)
+		})
+		commented := handleExpectedComment(t, mux, fmt.Sprintf(rebasedMessage, "main"))
+		err := rebaseHandler(context.Background(), cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "rebased", updated)
+		assert.Equal(t, true, deleted)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Forks Can Not Be Rebased", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		handleUpdatePullRequest(mux, "someone/paul")
+		commented := handleExpectedComment(t, mux, rebaseForkMessage)
+		err := rebaseHandler(context.Background(), cfg, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, *commented)
+	})
+	t.Run("Test Protected Branches Can Not Be Rebased", func(t *testing.T) {
+		protectedCfg := *cfg
+		protectedCfg.BranchDestroyer.ProtectedBranches = []string{"release-1.2"}
+		for _, branch := range []string{"main", "release-1.2"} {
+			mClient, mux, _, teardown := test.GetMockClient()
+			handleUpdatePullRequestBranch(mux, "Spazzy757/paul", branch)
+			commented := handleExpectedComment(t, mux, fmt.Sprintf(rebaseProtectedMessage, branch))
+			err := rebaseHandler(context.Background(), &protectedCfg, getHoldMockEvent(t), mClient)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, *commented)
+			teardown()
+		}
+	})
+	t.Run("Test Rebase Is Disabled", func(t *testing.T) {
+		mClient, mux, _, teardown := test.GetMockClient()
+		defer teardown()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+		})
+		err := rebaseHandler(context.Background(), &types.PaulConfig{}, getHoldMockEvent(t), mClient)
+		assert.Equal(t, nil, err)
+	})
+}
@@ -7,6 +7,22 @@ import (
 	"github.com/google/go-github/v49/github"
 )
 
+// handledEvents are the webhook event types IncomingWebhook acts on
+var handledEvents = map[string]bool{
+	"installation":                true,
+	"installation_repositories":   true,
+	"issue_comment":               true,
+	"pull_request":                true,
+	"pull_request_review":         true,
+	"pull_request_review_comment": true,
+	"push":                        true,
+}
+
+// HandlesEvent reports if the webhook event type is acted on
+func HandlesEvent(eventType string) bool {
+	return handledEvents[eventType]
+}
+
 // IncomingWebhook handles an incoming webhook request
 func IncomingWebhook(
 	ctx context.Context,
@@ -24,6 +40,16 @@ func IncomingWebhook(
 		err = IssueCommentHandler(ctx, e, client)
 	case *github.PullRequestEvent:
 		err = PullRequestHandler(ctx, e, client)
+	case *github.PullRequestReviewEvent:
+		err = PullRequestReviewHandler(ctx, e, client)
+	case *github.PullRequestReviewCommentEvent:
+		err = PullRequestReviewCommentHandler(ctx, e, client)
+	case *github.PushEvent:
+		err = PushHandler(ctx, e, client)
+	case *github.InstallationEvent:
+		err = InstallationHandler(ctx, e, client)
+	case *github.InstallationRepositoriesEvent:
+		err = InstallationRepositoriesHandler(ctx, e, client)
 	default:
 		break
 	}
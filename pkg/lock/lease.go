@@ -0,0 +1,204 @@
+package lock
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+	leasesPath         = "/apis/coordination.k8s.io/v1/namespaces/%s/leases"
+	microTimeFormat    = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+// Lease is the subset of a coordination.k8s.io/v1 Lease used for locking
+type Lease struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Metadata   LeaseMetadata `json:"metadata"`
+	Spec       LeaseSpec     `json:"spec"`
+}
+
+// LeaseMetadata is the object metadata of a Lease
+type LeaseMetadata struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+// LeaseSpec is the spec of a Lease
+type LeaseSpec struct {
+	HolderIdentity       string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds int    `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          string `json:"acquireTime,omitempty"`
+	RenewTime            string `json:"renewTime,omitempty"`
+}
+
+// LeaseLocker implements Locker using Kubernetes Leases
+// so that only one replica in a namespace holds a lock
+type LeaseLocker struct {
+	Identity  string
+	Namespace string
+	Host      string
+	Token     string
+	Client    *http.Client
+	now       func() time.Time
+}
+
+// NewLeaseLockerInCluster returns a LeaseLocker using the pods service account
+func NewLeaseLockerInCluster(identity string) (*LeaseLocker, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("kubernetes lock backend must be run inside a cluster")
+	}
+	token, err := os.ReadFile(serviceAccountPath + "/token")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account token: %s", err)
+	}
+	namespace := os.Getenv("LOCK_NAMESPACE")
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountPath + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("unable to read service account namespace: %s", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+	ca, err := os.ReadFile(serviceAccountPath + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account ca: %s", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	return &LeaseLocker{
+		Identity:  identity,
+		Namespace: namespace,
+		Host:      fmt.Sprintf("https://%s", strings.Join([]string{host, port}, ":")),
+		Token:     strings.TrimSpace(string(token)),
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+		now: time.Now,
+	}, nil
+}
+
+// TryLock takes the named Lease if it does not exist, has expired or is
+// already held by this identity. Conflicting writes from other replicas
+// are rejected by the API server and reported as not acquired
+func (l *LeaseLocker) TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	now := l.now()
+	lease, err := l.getLease(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if lease == nil {
+		lease = &Lease{
+			APIVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata: LeaseMetadata{
+				Name:      name,
+				Namespace: l.Namespace,
+			},
+		}
+		l.renew(lease, now, ttl)
+		return l.writeLease(ctx, http.MethodPost, l.leasesURL(), lease)
+	}
+	if lease.Spec.HolderIdentity != l.Identity && !leaseExpired(lease, now) {
+		return false, nil
+	}
+	l.renew(lease, now, ttl)
+	return l.writeLease(ctx, http.MethodPut, l.leasesURL()+"/"+name, lease)
+}
+
+func (l *LeaseLocker) renew(lease *Lease, now time.Time, ttl time.Duration) {
+	if lease.Spec.HolderIdentity != l.Identity {
+		lease.Spec.AcquireTime = now.Format(microTimeFormat)
+	}
+	lease.Spec.HolderIdentity = l.Identity
+	lease.Spec.LeaseDurationSeconds = int(ttl.Seconds())
+	lease.Spec.RenewTime = now.Format(microTimeFormat)
+}
+
+func leaseExpired(lease *Lease, now time.Time) bool {
+	renewed, err := time.Parse(microTimeFormat, lease.Spec.RenewTime)
+	if err != nil {
+		// a lease we can't read is treated as expired so it can be repaired
+		return true
+	}
+	duration := time.Duration(lease.Spec.LeaseDurationSeconds) * time.Second
+	return now.After(renewed.Add(duration))
+}
+
+func (l *LeaseLocker) leasesURL() string {
+	return l.Host + fmt.Sprintf(leasesPath, l.Namespace)
+}
+
+func (l *LeaseLocker) getLease(ctx context.Context, name string) (*Lease, error) {
+	res, err := l.do(ctx, http.MethodGet, l.leasesURL()+"/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code getting lease %s: %d", name, res.StatusCode)
+	}
+	lease := &Lease{}
+	err = json.NewDecoder(res.Body).Decode(lease)
+	return lease, err
+}
+
+func (l *LeaseLocker) writeLease(ctx context.Context, method, url string, lease *Lease) (bool, error) {
+	body, err := json.Marshal(lease)
+	if err != nil {
+		return false, err
+	}
+	res, err := l.do(ctx, method, url, body)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return true, nil
+	// Another replica created or updated the lease first
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, fmt.Errorf(
+			"unexpected status code writing lease %s: %d",
+			lease.Metadata.Name,
+			res.StatusCode,
+		)
+	}
+}
+
+func (l *LeaseLocker) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", l.Token))
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return l.Client.Do(req)
+}
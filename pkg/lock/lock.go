@@ -0,0 +1,102 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Spazzy757/paul/pkg/helpers"
+)
+
+// Locker guards work that should only be done by a single replica at a time
+type Locker interface {
+	// TryLock attempts to take the named lock for the ttl given, it returns
+	// false if the lock is currently held by someone else
+	TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error)
+}
+
+// NewFromEnv returns the Locker configured by the LOCK_BACKEND env-var,
+// when no backend is set every call to TryLock succeeds
+func NewFromEnv() (Locker, error) {
+	identity, err := getIdentity()
+	if err != nil {
+		return nil, err
+	}
+	switch backend := helpers.GetEnv("LOCK_BACKEND", ""); backend {
+	case "", "none":
+		return &noopLocker{}, nil
+	case "memory":
+		return NewMemoryLocker(identity), nil
+	case "kubernetes":
+		return NewLeaseLockerInCluster(identity)
+	default:
+		return nil, fmt.Errorf("unknown LOCK_BACKEND: %s", backend)
+	}
+}
+
+func getIdentity() (string, error) {
+	if identity := helpers.GetEnv("LOCK_IDENTITY", ""); identity != "" {
+		return identity, nil
+	}
+	return os.Hostname()
+}
+
+// noopLocker is used when running a single replica
+type noopLocker struct{}
+
+// TryLock always succeeds
+func (*noopLocker) TryLock(context.Context, string, time.Duration) (bool, error) {
+	return true, nil
+}
+
+type memoryLease struct {
+	holder    string
+	expiresAt time.Time
+}
+
+// MemoryLocker is an in process Locker, useful for tests and single replicas
+type MemoryLocker struct {
+	Identity string
+	mu       *sync.Mutex
+	leases   map[string]*memoryLease
+	now      func() time.Time
+}
+
+// NewMemoryLocker returns a MemoryLocker holding locks as identity
+func NewMemoryLocker(identity string) *MemoryLocker {
+	return &MemoryLocker{
+		Identity: identity,
+		mu:       &sync.Mutex{},
+		leases:   map[string]*memoryLease{},
+		now:      time.Now,
+	}
+}
+
+// WithIdentity returns a MemoryLocker that shares its locks with m
+// but holds them as a different identity
+func (m *MemoryLocker) WithIdentity(identity string) *MemoryLocker {
+	return &MemoryLocker{
+		Identity: identity,
+		mu:       m.mu,
+		leases:   m.leases,
+		now:      m.now,
+	}
+}
+
+// TryLock takes the lock if it is free, expired or already held by m
+func (m *MemoryLocker) TryLock(_ context.Context, name string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	lease, ok := m.leases[name]
+	if ok && lease.holder != m.Identity && now.Before(lease.expiresAt) {
+		return false, nil
+	}
+	m.leases[name] = &memoryLease{
+		holder:    m.Identity,
+		expiresAt: now.Add(ttl),
+	}
+	return true, nil
+}
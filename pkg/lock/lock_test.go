@@ -0,0 +1,181 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Run("Test No Backend Always Locks", func(t *testing.T) {
+		t.Setenv("LOCK_BACKEND", "")
+		locker, err := NewFromEnv()
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			ok, err := locker.TryLock(context.Background(), "test", time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+		}
+	})
+	t.Run("Test Memory Backend", func(t *testing.T) {
+		t.Setenv("LOCK_BACKEND", "memory")
+		locker, err := NewFromEnv()
+		require.NoError(t, err)
+		assert.IsType(t, &MemoryLocker{}, locker)
+	})
+	t.Run("Test Kubernetes Backend Fails Outside Cluster", func(t *testing.T) {
+		t.Setenv("LOCK_BACKEND", "kubernetes")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+		_, err := NewFromEnv()
+		assert.Error(t, err)
+	})
+	t.Run("Test Unknown Backend", func(t *testing.T) {
+		t.Setenv("LOCK_BACKEND", "carrier-pigeon")
+		_, err := NewFromEnv()
+		assert.Error(t, err)
+	})
+}
+
+func TestMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	first := NewMemoryLocker("first")
+	first.now = func() time.Time { return now }
+	second := first.WithIdentity("second")
+
+	ok, err := first.TryLock(ctx, "jobs", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	t.Run("Test Lock Held By Another Identity", func(t *testing.T) {
+		ok, err := second.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("Test Holder Can Renew", func(t *testing.T) {
+		ok, err := first.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("Test Other Locks Are Independent", func(t *testing.T) {
+		ok, err := second.TryLock(ctx, "other", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("Test Lock Can Be Taken Once Expired", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		ok, err := second.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = first.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+// leaseServer is a minimal fake of the Kubernetes Lease API
+func leaseServer(t *testing.T) (*httptest.Server, map[string]*Lease) {
+	mu := sync.Mutex{}
+	leases := map[string]*Lease{}
+	version := 0
+	prefix := fmt.Sprintf(leasesPath, "paul")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch r.Method {
+		case http.MethodGet:
+			lease, ok := leases[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(lease)
+		case http.MethodPost, http.MethodPut:
+			lease := &Lease{}
+			_ = json.NewDecoder(r.Body).Decode(lease)
+			existing, ok := leases[lease.Metadata.Name]
+			if r.Method == http.MethodPost && ok {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			if r.Method == http.MethodPut &&
+				(!ok || existing.Metadata.ResourceVersion != lease.Metadata.ResourceVersion) {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			version++
+			lease.Metadata.ResourceVersion = fmt.Sprint(version)
+			leases[lease.Metadata.Name] = lease
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(lease)
+		}
+	}))
+	return srv, leases
+}
+
+func TestLeaseLocker(t *testing.T) {
+	ctx := context.Background()
+	srv, leases := leaseServer(t)
+	defer srv.Close()
+	now := time.Now()
+	newLocker := func(identity string) *LeaseLocker {
+		return &LeaseLocker{
+			Identity:  identity,
+			Namespace: "paul",
+			Host:      srv.URL,
+			Token:     "token",
+			Client:    srv.Client(),
+			now:       func() time.Time { return now },
+		}
+	}
+	first := newLocker("first")
+	second := newLocker("second")
+
+	t.Run("Test Creates Lease When Missing", func(t *testing.T) {
+		ok, err := first.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "first", leases["jobs"].Spec.HolderIdentity)
+		assert.Equal(t, 60, leases["jobs"].Spec.LeaseDurationSeconds)
+	})
+	t.Run("Test Lease Held By Another Identity", func(t *testing.T) {
+		ok, err := second.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("Test Holder Can Renew", func(t *testing.T) {
+		ok, err := first.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("Test Expired Lease Can Be Taken", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		ok, err := second.TryLock(ctx, "jobs", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "second", leases["jobs"].Spec.HolderIdentity)
+	})
+	t.Run("Test Unexpected Status Code", func(t *testing.T) {
+		broken := newLocker("first")
+		broken.Host = srv.URL + "/broken"
+		broken.Client = &http.Client{Transport: http.DefaultTransport}
+		ok, err := broken.TryLock(ctx, "jobs", time.Minute)
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
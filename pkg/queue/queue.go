@@ -0,0 +1,203 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/Spazzy757/paul/pkg/changes"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrQueueFull is returned when a job can not be buffered
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrQueueClosed is returned when a job is added after Shutdown
+	ErrQueueClosed = errors.New("job queue is shut down")
+	// errJobPanicked is returned by an attempt that panicked
+	errJobPanicked = errors.New("job panicked")
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// Job is a unit of work, jobs with the same Key run in the order they were added
+type Job struct {
+	// Key is used to keep ordering, e.g. the repository full name
+	Key string
+	// ID is used when logging, e.g. the webhook delivery ID
+	ID  string
+	Run func(ctx context.Context) error
+	// Failed is called with the last error once the job is given up on,
+	// changed is true if the last attempt recorded a change with changes.Record
+	Failed func(err error, changed bool)
+}
+
+// Queue runs jobs on a bounded pool of workers, retrying transient failures
+type Queue struct {
+	// MaxRetries is the number of times a transient failure is retried
+	MaxRetries int
+	// Backoff is the wait before the first retry, it doubles on each attempt
+	Backoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+	// Retryable decides if a failed job should be retried
+	Retryable func(err error) bool
+
+	mu      sync.RWMutex
+	closed  bool
+	workers []chan Job
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// New starts a Queue with the given number of workers each buffering size jobs
+func New(workers, size int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		MaxRetries: defaultMaxRetries,
+		Backoff:    defaultBackoff,
+		MaxBackoff: defaultMaxBackoff,
+		Retryable:  IsTransient,
+		workers:    make([]chan Job, workers),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	for i := range q.workers {
+		q.workers[i] = make(chan Job, size)
+		q.wg.Add(1)
+		go q.work(q.workers[i])
+	}
+	return q
+}
+
+// Enqueue adds a job without blocking
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.workers[q.shard(job.Key)] <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting jobs and waits for queued jobs to finish, when the
+// context is done running jobs are cancelled and the context error is returned
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		for _, worker := range q.workers {
+			close(worker)
+		}
+	}
+	q.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// shard picks the worker for a key so jobs for the same key are never
+// processed concurrently or out of order
+func (q *Queue) shard(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(q.workers)))
+}
+
+func (q *Queue) work(jobs <-chan Job) {
+	defer q.wg.Done()
+	for job := range jobs {
+		q.run(job)
+	}
+}
+
+// run runs the job, jobs aren't idempotent so once a job has made a change
+// it isn't retried as that would repeat the change
+func (q *Queue) run(job Job) {
+	backoff := q.Backoff
+	for attempt := 0; ; attempt++ {
+		ctx, recorder := changes.NewContext(q.ctx)
+		err := job.attempt(ctx)
+		if err == nil {
+			return
+		}
+		fields := log.Fields{
+			"error":   err.Error(),
+			"job_id":  job.ID,
+			"key":     job.Key,
+			"attempt": attempt + 1,
+		}
+		if errors.Is(err, errJobPanicked) {
+			log.WithFields(fields).Error("job panicked, not retrying")
+			job.fail(err, recorder.Changed())
+			return
+		}
+		if recorder.Changed() {
+			log.WithFields(fields).Error("job failed after making changes, not retrying")
+			job.fail(err, true)
+			return
+		}
+		if attempt >= q.MaxRetries || !q.Retryable(err) {
+			log.WithFields(fields).Error("job failed")
+			job.fail(err, false)
+			return
+		}
+		wait := retryAfter(err, backoff)
+		if wait > q.MaxBackoff {
+			wait = q.MaxBackoff
+		}
+		log.WithFields(fields).Warn("job failed, retrying")
+		select {
+		case <-time.After(wait):
+		case <-q.ctx.Done():
+			log.WithFields(fields).Error("job cancelled during shutdown")
+			job.fail(err, false)
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// attempt runs the job once, a panic is recovered and returned as an error
+// so it doesn't take down the worker
+func (job Job) attempt(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errJobPanicked, r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (job Job) fail(err error, changed bool) {
+	if job.Failed != nil {
+		job.Failed(err, changed)
+	}
+}
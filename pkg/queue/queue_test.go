@@ -0,0 +1,213 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Spazzy757/paul/pkg/changes"
+	"github.com/google/go-github/v49/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	t.Run("Test Jobs With The Same Key Run In Order", func(t *testing.T) {
+		q := New(4, 100)
+		mu := &sync.Mutex{}
+		got := []int{}
+		for i := 0; i < 50; i++ {
+			i := i
+			err := q.Enqueue(Job{Key: "Spazzy757/paul", Run: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				got = append(got, i)
+				return nil
+			}})
+			assert.Equal(t, nil, err)
+		}
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 50, len(got))
+		for i, v := range got {
+			assert.Equal(t, i, v)
+		}
+	})
+	t.Run("Test Transient Failures Are Retried", func(t *testing.T) {
+		q := New(1, 1)
+		q.Backoff = time.Millisecond
+		attempts := 0
+		err := q.Enqueue(Job{Key: "a", Run: func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return &github.ErrorResponse{Response: mockResponse(http.StatusBadGateway)}
+			}
+			return nil
+		}})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 3, attempts)
+	})
+	t.Run("Test Failures After A Change Are Not Retried", func(t *testing.T) {
+		q := New(1, 1)
+		q.Backoff = time.Millisecond
+		attempts := 0
+		var failed error
+		changed := false
+		err := q.Enqueue(Job{
+			Key: "a",
+			Run: func(ctx context.Context) error {
+				attempts++
+				changes.Record(ctx)
+				return &github.ErrorResponse{Response: mockResponse(http.StatusBadGateway)}
+			},
+			Failed: func(err error, c bool) { failed, changed = err, c },
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 1, attempts)
+		assert.NotEqual(t, nil, failed)
+		assert.Equal(t, true, changed)
+	})
+	t.Run("Test Panics Are Recovered", func(t *testing.T) {
+		q := New(1, 2)
+		q.Backoff = time.Millisecond
+		attempts := 0
+		var failed error
+		err := q.Enqueue(Job{
+			Key: "a",
+			Run: func(context.Context) error {
+				attempts++
+				var args []string
+				_ = args[0]
+				return nil
+			},
+			Failed: func(err error, _ bool) { failed = err },
+		})
+		assert.Equal(t, nil, err)
+		ran := false
+		err = q.Enqueue(Job{Key: "a", Run: func(context.Context) error {
+			ran = true
+			return nil
+		}})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 1, attempts)
+		assert.ErrorIs(t, failed, errJobPanicked)
+		assert.Equal(t, true, ran)
+	})
+	t.Run("Test Permanent Failures Are Not Retried", func(t *testing.T) {
+		q := New(1, 1)
+		q.Backoff = time.Millisecond
+		attempts := 0
+		var failed error
+		changed := true
+		err := q.Enqueue(Job{
+			Key: "a",
+			Run: func(context.Context) error {
+				attempts++
+				return fmt.Errorf("bad config")
+			},
+			Failed: func(err error, c bool) { failed, changed = err, c },
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 1, attempts)
+		assert.EqualError(t, failed, "bad config")
+		assert.Equal(t, false, changed)
+	})
+	t.Run("Test Retries Are Limited", func(t *testing.T) {
+		q := New(1, 1)
+		q.Backoff = time.Millisecond
+		q.MaxRetries = 2
+		attempts := 0
+		err := q.Enqueue(Job{Key: "a", Run: func(context.Context) error {
+			attempts++
+			return &github.RateLimitError{Response: mockResponse(http.StatusForbidden)}
+		}})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, 3, attempts)
+	})
+	t.Run("Test Full Queue Rejects Jobs", func(t *testing.T) {
+		q := New(1, 1)
+		block := make(chan struct{})
+		started := make(chan struct{})
+		run := func(context.Context) error {
+			close(started)
+			<-block
+			return nil
+		}
+		assert.Equal(t, nil, q.Enqueue(Job{Key: "a", Run: run}))
+		<-started
+		noop := func(context.Context) error { return nil }
+		assert.Equal(t, nil, q.Enqueue(Job{Key: "a", Run: noop}))
+		assert.Equal(t, ErrQueueFull, q.Enqueue(Job{Key: "a", Run: noop}))
+		close(block)
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, ErrQueueClosed, q.Enqueue(Job{Key: "a", Run: noop}))
+	})
+	t.Run("Test Shutdown Cancels Running Jobs When Context Is Done", func(t *testing.T) {
+		q := New(1, 1)
+		started := make(chan struct{})
+		err := q.Enqueue(Job{Key: "a", Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}})
+		assert.Equal(t, nil, err)
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, q.Shutdown(ctx))
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "Server Error",
+			err: &github.ErrorResponse{
+				Response: &http.Response{StatusCode: http.StatusServiceUnavailable},
+			},
+			expected: true,
+		},
+		{
+			name: "Not Found",
+			err: &github.ErrorResponse{
+				Response: &http.Response{StatusCode: http.StatusNotFound},
+			},
+			expected: false,
+		},
+		{
+			name:     "Rate Limited",
+			err:      &github.RateLimitError{},
+			expected: true,
+		},
+		{
+			name:     "Secondary Rate Limit",
+			err:      fmt.Errorf("wrapped: %w", &github.AbuseRateLimitError{}),
+			expected: true,
+		},
+		{
+			name:     "Other Error",
+			err:      fmt.Errorf("test"),
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsTransient(tc.err))
+		})
+	}
+}
+
+func mockResponse(status int) *http.Response {
+	req, _ := http.NewRequest("GET", "https://api.github.com/", nil)
+	return &http.Response{StatusCode: status, Request: req}
+}
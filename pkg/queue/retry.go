@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v49/github"
+)
+
+// IsTransient reports if an error is likely to succeed when retried, this
+// covers rate limits, GitHub server errors and network timeouts
+func IsTransient(err error) bool {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var responseErr *github.ErrorResponse
+	var netErr net.Error
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		return true
+	case errors.As(err, &responseErr):
+		return responseErr.Response != nil &&
+			responseErr.Response.StatusCode >= http.StatusInternalServerError
+	case errors.As(err, &netErr):
+		return netErr.Timeout()
+	}
+	return false
+}
+
+// retryAfter returns how long GitHub asked us to wait, or the backoff given
+func retryAfter(err error, backoff time.Duration) time.Duration {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		if wait := time.Until(rateLimitErr.Rate.Reset.Time); wait > backoff {
+			return wait
+		}
+	case errors.As(err, &abuseErr):
+		if abuseErr.RetryAfter != nil && *abuseErr.RetryAfter > backoff {
+			return *abuseErr.RetryAfter
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	paulclient "github.com/Spazzy757/paul/pkg/client"
+	"github.com/Spazzy757/paul/pkg/queue"
+	"github.com/google/go-github/v49/github"
+	log "github.com/sirupsen/logrus"
+)
+
+// Error codes returned in the body of failed webhook deliveries
+const (
+	codeInvalidPayload      = "invalid_payload"
+	codeInvalidSignature    = "invalid_signature"
+	codeMissingInstallation = "missing_installation"
+	codeSecretUnavailable   = "secret_unavailable"
+	codeInstallationAuth    = "installation_auth_failed"
+	codeGithubError         = "github_error"
+	codeQueueUnavailable    = "queue_unavailable"
+	codeInternal            = "internal_error"
+)
+
+var errMissingInstallation = newWebhookError(
+	http.StatusBadRequest,
+	codeMissingInstallation,
+	errors.New("payload has no installation id"),
+)
+
+// webhookError gives an error the status code and error code to respond with
+type webhookError struct {
+	Status int
+	Code   string
+	Err    error
+}
+
+func (e *webhookError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *webhookError) Unwrap() error {
+	return e.Err
+}
+
+func newWebhookError(status int, code string, err error) error {
+	return &webhookError{Status: status, Code: code, Err: err}
+}
+
+// errorResponse is the body sent when a delivery fails
+type errorResponse struct {
+	Error      string `json:"error"`
+	Code       string `json:"code"`
+	DeliveryID string `json:"delivery_id,omitempty"`
+}
+
+// classifyError returns the status code and error code for an error, errors
+// that were not expected are treated as internal failures
+func classifyError(err error) (int, string) {
+	var webhookErr *webhookError
+	var accessTokenErr *paulclient.AccessTokenError
+	switch {
+	case errors.As(err, &webhookErr):
+		return webhookErr.Status, webhookErr.Code
+	case errors.Is(err, queue.ErrQueueFull), errors.Is(err, queue.ErrQueueClosed):
+		return http.StatusServiceUnavailable, codeQueueUnavailable
+	case errors.As(err, &accessTokenErr):
+		return http.StatusInternalServerError, codeInstallationAuth
+	case errors.As(err, new(*github.ErrorResponse)),
+		errors.As(err, new(*github.RateLimitError)),
+		errors.As(err, new(*github.AbuseRateLimitError)):
+		return http.StatusInternalServerError, codeGithubError
+	default:
+		return http.StatusInternalServerError, codeInternal
+	}
+}
+
+func handleError(w http.ResponseWriter, deliveryID string, err error) bool {
+	if err == nil {
+		return false
+	}
+	status, code := classifyError(err)
+	fields := log.Fields{
+		"error":       err.Error(),
+		"code":        code,
+		"status":      status,
+		"delivery_id": deliveryID,
+	}
+	if status >= http.StatusInternalServerError {
+		log.WithFields(fields).Error("webhook error occurred")
+	} else {
+		log.WithFields(fields).Warn("webhook rejected")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Error:      err.Error(),
+		Code:       code,
+		DeliveryID: deliveryID,
+	})
+	return true
+}
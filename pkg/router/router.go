@@ -1,65 +1,202 @@
 package router
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/Spazzy757/paul/pkg/changes"
 	paulclient "github.com/Spazzy757/paul/pkg/client"
+	"github.com/Spazzy757/paul/pkg/config"
+	"github.com/Spazzy757/paul/pkg/delivery"
 	paulgithub "github.com/Spazzy757/paul/pkg/github"
-	"github.com/Spazzy757/paul/pkg/helpers"
+	"github.com/Spazzy757/paul/pkg/queue"
 	"github.com/google/go-github/v49/github"
 	"github.com/gorilla/mux"
 )
 
+const (
+	deliveryStoreSize = 10000
+	deliveryTTL       = time.Hour
+)
+
+// deliveries holds the webhook deliveries already handled, GitHub redelivers
+// webhooks it thinks timed out
+var deliveries = delivery.NewStore(deliveryStoreSize, deliveryTTL)
+
+// jobs processes webhooks in the background when set, otherwise webhooks are
+// handled before responding
+var jobs *queue.Queue
+
+// SetQueue makes the webhook handler enqueue events onto q and respond with
+// 202 Accepted straight away
+func SetQueue(q *queue.Queue) {
+	jobs = q
+}
+
 // GetRouter .
 func GetRouter() *mux.Router {
 	r := mux.NewRouter()
 	r.HandleFunc("/webhooks", GithubWebHookHandler)
+	r.HandleFunc("/schema/paul.json", SchemaHandler).Methods(http.MethodGet)
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./web/dist")))
 	return r
 }
 
 // GithubWebHookHandler .
 func GithubWebHookHandler(w http.ResponseWriter, r *http.Request) {
+	deliveryID := github.DeliveryID(r)
 	// handle authentication
-	secretKey := helpers.GetEnv("SECRET_KEY", "")
-	payload, validationErr := github.ValidatePayload(r, []byte(secretKey))
-	if handleError(w, validationErr) {
+	secretKey, err := paulclient.GetWebhookSecret()
+	if err != nil {
+		handleError(w, deliveryID, newWebhookError(
+			http.StatusInternalServerError, codeSecretUnavailable, err,
+		))
+		return
+	}
+	payload, err := validatePayload(r, secretKey)
+	if handleError(w, deliveryID, err) {
+		return
+	}
+	eventType := github.WebHookType(r)
+	if !paulgithub.HandlesEvent(eventType) {
+		log.WithFields(log.Fields{
+			"event":       eventType,
+			"delivery_id": deliveryID,
+		}).Debug("ignoring webhook event")
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	instllationID, err := getInstallationId(payload)
-	if handleError(w, err) {
+	if deliveryID != "" && !deliveries.Reserve(deliveryID) {
+		log.WithFields(log.Fields{
+			"delivery_id": deliveryID,
+		}).Info("skipping duplicate webhook delivery")
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
-	gClient, err := paulclient.GetInstallationClient(instllationID)
-	ctx := context.Background()
-	if handleError(w, err) {
+	if jobs != nil {
+		err = enqueueWebhook(r, payload, deliveryID)
+		if err != nil && deliveryID != "" {
+			deliveries.Release(deliveryID)
+		}
+		if handleError(w, deliveryID, err) {
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
 		return
 	}
-	err = paulgithub.IncomingWebhook(ctx, r, payload, gClient)
-	if handleError(w, err) {
+	ctx, recorder := changes.NewContext(context.Background())
+	err = handleWebhook(ctx, r, payload)
+	if err != nil && deliveryID != "" && !recorder.Changed() {
+		// allow GitHub to redeliver webhooks that failed without changing anything
+		deliveries.Release(deliveryID)
+	}
+	if handleError(w, deliveryID, err) {
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
-func handleError(w http.ResponseWriter, err error) bool {
+// validatePayload returns the JSON payload of the webhook, checking the
+// request's signature against the webhook secret
+func validatePayload(r *http.Request, secretKey []byte) ([]byte, error) {
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		log.WithFields(log.Fields{
-			"error": err.Error(),
-		}).Error("webhook error occurred")
-		w.WriteHeader(http.StatusBadRequest)
-		return true
+		return nil, newWebhookError(http.StatusBadRequest, codeInvalidPayload, err)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, newWebhookError(http.StatusBadRequest, codeInvalidPayload, err)
+	}
+	payload, err := github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), "", nil)
+	if err != nil {
+		return nil, newWebhookError(http.StatusBadRequest, codeInvalidPayload, err)
+	}
+	signature := r.Header.Get(github.SHA256SignatureHeader)
+	if signature == "" {
+		signature = r.Header.Get(github.SHA1SignatureHeader)
+	}
+	if err := github.ValidateSignature(signature, body, secretKey); err != nil {
+		return nil, newWebhookError(http.StatusUnauthorized, codeInvalidSignature, err)
 	}
-	return false
+	return payload, nil
+}
+
+func handleWebhook(ctx context.Context, r *http.Request, payload []byte) error {
+	p, err := getPayload(payload)
+	if err != nil {
+		return err
+	}
+	instllationID, err := p.installationID()
+	if err != nil {
+		return err
+	}
+	var gClient *github.Client
+	if paulgithub.RequiresClient(github.WebHookType(r), p.Action) {
+		gClient, err = paulclient.GetInstallationClient(instllationID)
+		if err != nil {
+			return err
+		}
+	}
+	return paulgithub.IncomingWebhook(ctx, r, payload, gClient)
+}
+
+// enqueueWebhook queues the webhook, events for a repository are handled in
+// the order they were received
+func enqueueWebhook(r *http.Request, payload []byte, deliveryID string) error {
+	p, err := getPayload(payload)
+	if err != nil {
+		return err
+	}
+	instllationID, err := p.installationID()
+	if err != nil {
+		return err
+	}
+	key := p.Repository.FullName
+	if key == "" {
+		key = fmt.Sprintf("installation/%d", instllationID)
+	}
+	// the request is finished with once we respond, keep a copy of the headers
+	req := r.Clone(context.Background())
+	return jobs.Enqueue(queue.Job{
+		Key: key,
+		ID:  deliveryID,
+		Run: func(ctx context.Context) error {
+			return handleWebhook(ctx, req, payload)
+		},
+		// a redelivery would repeat any changes made so the delivery is
+		// only released when nothing was changed
+		Failed: func(_ error, changed bool) {
+			if deliveryID != "" && !changed {
+				deliveries.Release(deliveryID)
+			}
+		},
+	})
+}
+
+// SchemaHandler serves the JSON Schema for PAUL.yaml
+func SchemaHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	_ = json.NewEncoder(w).Encode(config.Schema())
 }
 
 // Payload is used to get the installation ID from payload
 type Payload struct {
+	Action       string       `json:"action"`
 	Installation Installation `json:"installation"`
+	Repository   Repository   `json:"repository"`
+}
+
+// Repository is used to get the repository name from the payload
+type Repository struct {
+	FullName string `json:"full_name"`
 }
 
 // Installation is used to get the installation ID from the payload
@@ -68,10 +205,24 @@ type Installation struct {
 }
 
 func getInstallationId(payload []byte) (int64, error) {
-	p := &Payload{}
-	err := json.Unmarshal(payload, p)
+	p, err := getPayload(payload)
 	if err != nil {
 		return 0, err
 	}
-	return p.Installation.ID, err
+	return p.installationID()
+}
+
+func (p *Payload) installationID() (int64, error) {
+	if p.Installation.ID == 0 {
+		return 0, errMissingInstallation
+	}
+	return p.Installation.ID, nil
+}
+
+func getPayload(payload []byte) (*Payload, error) {
+	p := &Payload{}
+	if err := json.Unmarshal(payload, p); err != nil {
+		return nil, newWebhookError(http.StatusBadRequest, codeInvalidPayload, err)
+	}
+	return p, nil
 }
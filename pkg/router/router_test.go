@@ -2,9 +2,11 @@ package router
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,7 +14,10 @@ import (
 	"reflect"
 	"testing"
 
+	paulclient "github.com/Spazzy757/paul/pkg/client"
+	"github.com/Spazzy757/paul/pkg/queue"
 	"github.com/Spazzy757/paul/pkg/test"
+	"github.com/google/go-github/v49/github"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
@@ -40,17 +45,80 @@ func TestGetInstallationId(t *testing.T) {
 }
 
 func TestHandleError(t *testing.T) {
-	w := httptest.NewRecorder()
 	t.Run("Test Handle Error with no Error", func(t *testing.T) {
-		check := handleError(w, nil)
+		w := httptest.NewRecorder()
+		check := handleError(w, "", nil)
 		assert.Equal(t, false, check)
+		assert.Equal(t, 200, w.Result().StatusCode)
 	})
 	t.Run("Test Handle Error with Error", func(t *testing.T) {
-		check := handleError(w, fmt.Errorf("test"))
+		w := httptest.NewRecorder()
+		check := handleError(w, "1234", fmt.Errorf("test"))
 		assert.Equal(t, true, check)
+		response := w.Result()
+		assert.Equal(t, 500, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		body := errorResponse{}
+		assert.Equal(t, nil, json.NewDecoder(response.Body).Decode(&body))
+		assert.Equal(t, errorResponse{
+			Error:      "test",
+			Code:       codeInternal,
+			DeliveryID: "1234",
+		}, body)
 	})
 }
 
+func TestClassifyError(t *testing.T) {
+	mockResponse := &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Request:    httptest.NewRequest("GET", "/", nil),
+	}
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Missing Installation",
+			err:            errMissingInstallation,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   codeMissingInstallation,
+		},
+		{
+			name:           "Queue Full",
+			err:            queue.ErrQueueFull,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   codeQueueUnavailable,
+		},
+		{
+			name:           "Access Token Error",
+			err:            &paulclient.AccessTokenError{Err: paulclient.ErrInstallationSuspended},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   codeInstallationAuth,
+		},
+		{
+			name:           "Github Error",
+			err:            fmt.Errorf("labels: %w", &github.ErrorResponse{Response: mockResponse}),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   codeGithubError,
+		},
+		{
+			name:           "Unknown Error",
+			err:            fmt.Errorf("test"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   codeInternal,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, code := classifyError(tc.err)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedCode, code)
+		})
+	}
+}
+
 func TestGetRouter(t *testing.T) {
 	t.Run("Test Returns the Router", func(t *testing.T) {
 		r := GetRouter()
@@ -59,6 +127,20 @@ func TestGetRouter(t *testing.T) {
 	})
 }
 
+func TestSchemaHandler(t *testing.T) {
+	t.Run("Test Serves The Config Schema", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/schema/paul.json", nil)
+		GetRouter().ServeHTTP(w, req)
+		response := w.Result()
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, "application/schema+json", response.Header.Get("Content-Type"))
+		schema := map[string]interface{}{}
+		assert.Equal(t, nil, json.NewDecoder(response.Body).Decode(&schema))
+		assert.Contains(t, schema, "properties")
+	})
+}
+
 func TestGithubWebHookHandler(t *testing.T) {
 	os.Setenv("SECRET_KEY", "test")
 	t.Run("Test Fails on Get Client", func(t *testing.T) {
@@ -72,7 +154,7 @@ func TestGithubWebHookHandler(t *testing.T) {
 
 		GithubWebHookHandler(w, req)
 		response := w.Result()
-		assert.Equal(t, 400, response.StatusCode)
+		assert.Equal(t, 500, response.StatusCode)
 	})
 	t.Run("Test Fails on Validation", func(t *testing.T) {
 		webhookPayload := test.GetMockPayload("label-command")
@@ -85,7 +167,7 @@ func TestGithubWebHookHandler(t *testing.T) {
 
 		GithubWebHookHandler(w, req)
 		response := w.Result()
-		assert.Equal(t, 400, response.StatusCode)
+		assert.Equal(t, 401, response.StatusCode)
 	})
 	t.Run("Test Fails on Getting InstallationID", func(t *testing.T) {
 		webhookPayload := []byte(`
@@ -96,14 +178,115 @@ func TestGithubWebHookHandler(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
 		req.Header.Set("X-GitHub-Event", "issue_comment")
 		req.Header.Set("Content-Type", "application/json")
-		signature := generateGitHubSha("not_valid", webhookPayload)
+		signature := generateGitHubSha("test", webhookPayload)
 		req.Header.Set("X-Hub-Signature", signature)
 
 		GithubWebHookHandler(w, req)
 		response := w.Result()
 		assert.Equal(t, 400, response.StatusCode)
 	})
+	t.Run("Test Fails on Missing Signature", func(t *testing.T) {
+		webhookPayload := test.GetMockPayload("label-command")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("X-GitHub-Delivery", "unsigned-delivery")
+		req.Header.Set("Content-Type", "application/json")
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 401, response.StatusCode)
+		body := errorResponse{}
+		assert.Equal(t, nil, json.NewDecoder(response.Body).Decode(&body))
+		assert.Equal(t, codeInvalidSignature, body.Code)
+		assert.Equal(t, "unsigned-delivery", body.DeliveryID)
+	})
+	t.Run("Test Fails on Unsupported Content Type", func(t *testing.T) {
+		webhookPayload := test.GetMockPayload("label-command")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 400, response.StatusCode)
+	})
+	t.Run("Test Ignores Unhandled Events", func(t *testing.T) {
+		webhookPayload := []byte(`{"zen": "Keep it logically awesome."}`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "ping")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 204, response.StatusCode)
+	})
+	t.Run("Test Handles Deleted Installation Without A Client", func(t *testing.T) {
+		webhookPayload := []byte(`{
+          "action": "deleted",
+          "installation": {"id": 1, "account": {"login": "Spazzy757"}}
+        }`)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "installation")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 200, response.StatusCode)
+	})
+	t.Run("Test Skips Duplicate Delivery", func(t *testing.T) {
+		webhookPayload := test.GetMockPayload("label-command")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("X-GitHub-Delivery", "duplicate-delivery")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
+		assert.Equal(t, true, deliveries.Reserve("duplicate-delivery"))
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 202, response.StatusCode)
+	})
+	t.Run("Test Failed Delivery Can Be Redelivered", func(t *testing.T) {
+		webhookPayload := test.GetMockPayload("label-command")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("X-GitHub-Delivery", "failed-delivery")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
+
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 500, response.StatusCode)
+		assert.Equal(t, true, deliveries.Reserve("failed-delivery"))
+	})
+	t.Run("Test Queues Webhook And Responds Accepted", func(t *testing.T) {
+		q := queue.New(1, 1)
+		SetQueue(q)
+		defer SetQueue(nil)
+		webhookPayload := test.GetMockPayload("label-command")
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(webhookPayload))
+		req.Header.Set("X-GitHub-Event", "issue_comment")
+		req.Header.Set("X-GitHub-Delivery", "queued-delivery")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Hub-Signature", generateGitHubSha("test", webhookPayload))
 
+		GithubWebHookHandler(w, req)
+		response := w.Result()
+		assert.Equal(t, 202, response.StatusCode)
+		// getting the client fails in the background which frees the delivery
+		assert.Equal(t, nil, q.Shutdown(context.Background()))
+		assert.Equal(t, true, deliveries.Reserve("queued-delivery"))
+	})
 }
 
 func generateGitHubSha(secret string, body []byte) string {
@@ -2,21 +2,40 @@ package scheduler
 
 import (
 	"context"
+	"time"
 
 	paulclient "github.com/Spazzy757/paul/pkg/client"
 	paulgithub "github.com/Spazzy757/paul/pkg/github"
 	"github.com/Spazzy757/paul/pkg/helpers"
+	"github.com/Spazzy757/paul/pkg/lock"
 	"github.com/google/go-github/v49/github"
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
 )
 
+const pullRequestsLock = "paul-pull-requests-schedule"
+
 // AddSchedule Runs scheduled tasks for Paul
-func AddSchedule(c *cron.Cron) {
+// the locker makes sure only a single replica runs each scheduled job
+func AddSchedule(c *cron.Cron, locker lock.Locker) error {
 	stalePullRequestsSchedule := helpers.GetEnv("STALE_CHECK_SCHEDULE", "0 * * * *")
-	_, _ = c.AddFunc(stalePullRequestsSchedule, func() {
-		gClient, _ := paulclient.GetClient()
+	// The lock is held for the ttl rather than released when the job finishes
+	// so replicas whose schedule fires slightly later don't run it again
+	lockTTL, err := time.ParseDuration(helpers.GetEnv("LOCK_TTL", "10m"))
+	if err != nil {
+		return err
+	}
+	_, err = c.AddFunc(stalePullRequestsSchedule, func() {
 		ctx := context.Background()
+		acquired, err := locker.TryLock(ctx, pullRequestsLock, lockTTL)
+		if handleErr(err) {
+			return
+		}
+		if !acquired {
+			log.Info("scheduled jobs are running on another replica")
+			return
+		}
+		gClient, _ := paulclient.GetClient()
 		installations, _, err := gClient.Apps.ListInstallations(ctx, &github.ListOptions{})
 		if handleErr(err) {
 			return
@@ -29,6 +48,7 @@ func AddSchedule(c *cron.Cron) {
 			paulgithub.PullRequestsScheduledJobs(ctx, gInstallationClient)
 		}
 	})
+	return err
 }
 
 func handleErr(err error) bool {
@@ -1,16 +1,41 @@
 package types
 
 import (
+	"strings"
+
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	// DefaultEmptyDescriptionMessage is used when the empty description check has no message
+	DefaultEmptyDescriptionMessage = "There seems to be no description in your Pull Request.Please add an understanding of what this change proposes to do and why it is needed"
+)
+
+// BuiltinCommands are the commands Paul handles, each has a handler in the
+// github package and custom commands can't replace them
+var BuiltinCommands = []string{
+	"cat", "dog", "giphy", "label", "remove-label", "milestone", "priority",
+	"cherry-pick", "update-branch", "rebase", "retitle", "edit-description",
+	"approve", "lgtm", "retest", "close", "reopen", "lock", "unlock",
+	"hold", "unhold", "merge", "assign",
+}
+
 //PaulConfig defines the struct for type
 type PaulConfig struct {
-	Maintainers           []string              `yaml:"maintainers,omitempty"`
-	PullRequests          PullRequests          `yaml:"pull_requests,omitempty"`
-	Labels                bool                  `yaml:"labels,omitempty"`
-	BranchDestroyer       BranchDestroyer       `yaml:"branch_destroyer,omitempty"`
-	EmptyDescriptionCheck EmptyDescriptionCheck `yaml:"empty_description_check,omitempty"`
+	Extends               string                   `yaml:"extends,omitempty"`
+	Maintainers           []string                 `yaml:"maintainers,omitempty"`
+	PullRequests          PullRequests             `yaml:"pull_requests,omitempty"`
+	Labels                Labels                   `yaml:"labels,omitempty"`
+	BranchDestroyer       BranchDestroyer          `yaml:"branch_destroyer,omitempty"`
+	EmptyDescriptionCheck EmptyDescriptionCheck    `yaml:"empty_description_check,omitempty"`
+	CustomCommands        map[string]CustomCommand `yaml:"custom_commands,omitempty"`
+}
+
+// CustomCommand replies with a canned response or is an alias for a
+// built-in command, responses can use {{sender}}, {{number}} and {{repo}}
+type CustomCommand struct {
+	Response string `yaml:"response,omitempty"`
+	Alias    string `yaml:"alias,omitempty"`
 }
 
 //EmptyDescriptionCheck config for empty PR checks
@@ -18,6 +43,73 @@ type EmptyDescriptionCheck struct {
 	Enabled  bool   `yaml:"enabled,omitempty"`
 	Enforced bool   `yaml:"enforced,omitempty"`
 	Message  string `yaml:"message,omitempty"`
+	// Template is set as the description of Pull Requests opened without one
+	Template string `yaml:"template,omitempty"`
+}
+
+// Labels configures the /label commands and the labels Paul keeps in sync,
+// setting labels to true or false is short for enabled
+type Labels struct {
+	Enabled     bool              `yaml:"enabled,omitempty"`
+	Definitions []LabelDefinition `yaml:"definitions,omitempty"`
+	// Prefixes become commands i.e with kind "/kind bug" adds "kind/bug"
+	// and "/remove-kind bug" removes it
+	Prefixes []string `yaml:"prefixes,omitempty"`
+	// Priorities map /priority levels to labels i.e "/priority high"
+	// adds "priority/high" with high: priority/high
+	Priorities map[string]string `yaml:"priorities,omitempty"`
+}
+
+// LabelDefinition is a label Paul creates and keeps up to date,
+// aliases can be used in place of the name with /label
+type LabelDefinition struct {
+	Name        string   `yaml:"name"`
+	Color       string   `yaml:"color,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Aliases     []string `yaml:"aliases,omitempty"`
+}
+
+// UnmarshalYAML accepts either a bool or the labels block
+func (l *Labels) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		*l = Labels{Enabled: enabled}
+		return nil
+	}
+	type plain Labels
+	return unmarshal((*plain)(l))
+}
+
+// Lookup returns the definition for a label name or alias, ignoring case
+func (l Labels) Lookup(name string) (LabelDefinition, bool) {
+	for _, definition := range l.Definitions {
+		if strings.EqualFold(definition.Name, name) {
+			return definition, true
+		}
+		for _, alias := range definition.Aliases {
+			if strings.EqualFold(alias, name) {
+				return definition, true
+			}
+		}
+	}
+	return LabelDefinition{}, false
+}
+
+// IsPrefix checks if the command is one of the label prefixes
+func (l Labels) IsPrefix(command string) bool {
+	for _, prefix := range l.Prefixes {
+		if prefix == command {
+			return true
+		}
+	}
+	return false
+}
+
+// RemovesPrefix returns the prefix a remove command is for i.e "kind" for
+// "remove-kind"
+func (l Labels) RemovesPrefix(command string) (string, bool) {
+	prefix := strings.TrimPrefix(command, "remove-")
+	return prefix, prefix != command && l.IsPrefix(prefix)
 }
 
 //PullRequests struct
@@ -33,6 +125,23 @@ type PullRequests struct {
 	LimitPullRequests   LimitPullRequests `yaml:"limit_pull_requests,omitempty"`
 	DCOCheck            bool              `yaml:"dco_check,omitempty"`
 	VerifiedCommitCheck bool              `yaml:"verified_commit_check,omitempty"`
+	Approvals           Approvals         `yaml:"approvals,omitempty"`
+	Retest              Retest            `yaml:"retest,omitempty"`
+	AllowRebase         bool              `yaml:"allow_rebase,omitempty"`
+}
+
+// Retest enables the /retest command, when checks are listed
+// only those checks are re-run
+type Retest struct {
+	Enabled bool     `yaml:"enabled,omitempty"`
+	Checks  []string `yaml:"checks,omitempty"`
+}
+
+// Approvals sets how many maintainers have to approve a Pull Request,
+// with a review or /lgtm, before it can be merged
+type Approvals struct {
+	Required      int  `yaml:"required,omitempty"`
+	DismissOnPush bool `yaml:"dismiss_on_push,omitempty"`
 }
 
 //LimitPullRequests struct
@@ -46,6 +155,30 @@ type BranchDestroyer struct {
 	ProtectedBranches []string `yaml:"protected_branches,omitempty"`
 }
 
+// IsBuiltinCommand checks if the command is one Paul handles
+func IsBuiltinCommand(command string) bool {
+	for _, c := range BuiltinCommands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCommand checks if the command is built-in or one of the commands
+// for the label prefixes i.e /kind and /remove-kind
+func (c PaulConfig) IsCommand(command string) bool {
+	_, removesPrefix := c.Labels.RemovesPrefix(command)
+	return IsBuiltinCommand(command) || c.Labels.IsPrefix(command) || removesPrefix
+}
+
+// SetDefaults fills in the values paul uses when a setting is not configured
+func (pc *PaulConfig) SetDefaults() {
+	if pc.EmptyDescriptionCheck.Enabled && pc.EmptyDescriptionCheck.Message == "" {
+		pc.EmptyDescriptionCheck.Message = DefaultEmptyDescriptionMessage
+	}
+}
+
 //LoadConfig loads the config for the type PaulConfig
 func (pc *PaulConfig) LoadConfig(config []byte) error {
 	err := yaml.Unmarshal(config, pc)
@@ -28,7 +28,7 @@ func TestLoadConfig(t *testing.T) {
 		assert.NotEqual(t, paulConfig.PullRequests.AllowApproval, false)
 	})
 	t.Run("Test Loading Config - Labels", func(t *testing.T) {
-		assert.NotEqual(t, paulConfig.Labels, false)
+		assert.NotEqual(t, paulConfig.Labels.Enabled, false)
 	})
 	t.Run("Test Loading Config - LimitPullRequests", func(t *testing.T) {
 		assert.NotEqual(t, paulConfig.PullRequests.LimitPullRequests.MaxNumber, nil)
@@ -67,3 +67,86 @@ func TestLoadConfigFails(t *testing.T) {
 	err := paulConfig.LoadConfig([]byte(`% ^ & HHH`))
 	assert.NotEqual(t, nil, err)
 }
+
+func TestSetDefaults(t *testing.T) {
+	t.Run("Test Defaults Are Set", func(t *testing.T) {
+		paulConfig := PaulConfig{EmptyDescriptionCheck: EmptyDescriptionCheck{Enabled: true}}
+		paulConfig.SetDefaults()
+		assert.Equal(t, DefaultEmptyDescriptionMessage, paulConfig.EmptyDescriptionCheck.Message)
+	})
+	t.Run("Test Configured Values Are Kept", func(t *testing.T) {
+		paulConfig := PaulConfig{
+			EmptyDescriptionCheck: EmptyDescriptionCheck{Enabled: true, Message: "test"},
+		}
+		paulConfig.SetDefaults()
+		assert.Equal(t, "test", paulConfig.EmptyDescriptionCheck.Message)
+	})
+}
+
+func TestLoadLabels(t *testing.T) {
+	t.Run("Test Labels As Bool", func(t *testing.T) {
+		var paulConfig PaulConfig
+		err := paulConfig.LoadConfig([]byte(`labels: true`))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Labels{Enabled: true}, paulConfig.Labels)
+	})
+	t.Run("Test Labels As Block", func(t *testing.T) {
+		var paulConfig PaulConfig
+		err := paulConfig.LoadConfig([]byte(`
+labels:
+  enabled: true
+  definitions:
+    - name: bug
+      color: d73a4a
+      description: Something isn't working
+      aliases:
+        - defect
+`))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Labels{
+			Enabled: true,
+			Definitions: []LabelDefinition{{
+				Name:        "bug",
+				Color:       "d73a4a",
+				Description: "Something isn't working",
+				Aliases:     []string{"defect"},
+			}},
+		}, paulConfig.Labels)
+		definition, ok := paulConfig.Labels.Lookup("DEFECT")
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "bug", definition.Name)
+		_, ok = paulConfig.Labels.Lookup("wontfix")
+		assert.Equal(t, false, ok)
+	})
+	t.Run("Test Labels Of The Wrong Type", func(t *testing.T) {
+		var paulConfig PaulConfig
+		err := paulConfig.LoadConfig([]byte(`labels: [bug]`))
+		assert.NotEqual(t, nil, err)
+	})
+}
+
+func TestLabelsIsPrefix(t *testing.T) {
+	labels := Labels{Prefixes: []string{"kind", "area"}}
+	assert.Equal(t, true, labels.IsPrefix("kind"))
+	assert.Equal(t, false, labels.IsPrefix("label"))
+}
+
+func TestLabelsRemovesPrefix(t *testing.T) {
+	labels := Labels{Prefixes: []string{"kind", "area"}}
+	prefix, ok := labels.RemovesPrefix("remove-kind")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "kind", prefix)
+	_, ok = labels.RemovesPrefix("kind")
+	assert.Equal(t, false, ok)
+	_, ok = labels.RemovesPrefix("remove-label")
+	assert.Equal(t, false, ok)
+}
+
+func TestPaulConfigIsCommand(t *testing.T) {
+	cfg := PaulConfig{Labels: Labels{Prefixes: []string{"kind"}}}
+	assert.Equal(t, true, cfg.IsCommand("label"))
+	assert.Equal(t, true, cfg.IsCommand("kind"))
+	assert.Equal(t, true, cfg.IsCommand("remove-kind"))
+	assert.Equal(t, false, cfg.IsCommand("remove-area"))
+	assert.Equal(t, false, cfg.IsCommand("ship-it"))
+}